@@ -0,0 +1,12 @@
+package main
+
+import (
+	"github.com/cartyc/terraform-provider-rke/rke"
+	"github.com/hashicorp/terraform/plugin"
+)
+
+func main() {
+	plugin.Serve(&plugin.ServeOpts{
+		ProviderFunc: rke.Provider,
+	})
+}
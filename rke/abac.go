@@ -0,0 +1,238 @@
+package rke
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rancher/rke/hosts"
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// abacPolicyFilePath is where the rendered ABAC policy document is written on every
+// controlplane node, and the value injected into kube-apiserver's
+// --authorization-policy-file flag.
+const abacPolicyFilePath = "/etc/kubernetes/abac-policy.jsonl"
+
+// abacPolicyExtraBind mounts abacPolicyFilePath straight through into the kube-apiserver
+// container, read-only, at the same path --authorization-policy-file points at. Writing
+// the file to the host alone isn't enough: without this bind the host path is never
+// visible inside the container and kube-apiserver fails to find its policy file.
+const abacPolicyExtraBind = abacPolicyFilePath + ":" + abacPolicyFilePath + ":ro"
+
+// abacPolicy is one entry of the authorization.policies list.
+type abacPolicy struct {
+	User            string
+	Group           string
+	Namespace       string
+	Resource        string
+	APIGroup        string
+	NonResourcePath string
+	Readonly        bool
+}
+
+type abacPolicySpec struct {
+	User            string `json:"user,omitempty"`
+	Group           string `json:"group,omitempty"`
+	Namespace       string `json:"namespace,omitempty"`
+	Resource        string `json:"resource,omitempty"`
+	APIGroup        string `json:"apiGroup,omitempty"`
+	NonResourcePath string `json:"nonResourcePath,omitempty"`
+	Readonly        bool   `json:"readonly,omitempty"`
+}
+
+type abacPolicyDoc struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Spec       abacPolicySpec `json:"spec"`
+}
+
+// abacPolicyDocument renders policies into the JSON-lines document kube-apiserver's
+// --authorization-policy-file expects: one abac.authorization.kubernetes.io/v1beta1
+// Policy object per line.
+func abacPolicyDocument(policies []abacPolicy) (string, error) {
+	lines := make([]string, len(policies))
+	for i, p := range policies {
+		doc := abacPolicyDoc{
+			APIVersion: "abac.authorization.kubernetes.io/v1beta1",
+			Kind:       "Policy",
+			Spec: abacPolicySpec{
+				User:            p.User,
+				Group:           p.Group,
+				Namespace:       p.Namespace,
+				Resource:        p.Resource,
+				APIGroup:        p.APIGroup,
+				NonResourcePath: p.NonResourcePath,
+				Readonly:        p.Readonly,
+			},
+		}
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return "", err
+		}
+		lines[i] = string(b)
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// writeABACPolicyFile distributes the rendered ABAC policy document to every
+// controlplane node over SSH, so the file is already in place when kube-apiserver
+// starts with --authorization-policy-file. Each node is wrapped in a hosts.Host, but
+// the write goes over a separate golang.org/x/crypto/ssh connection opened by
+// sshWriteFile from that node's address, port, user and SSH key settings; RKE's own
+// dialers are not used.
+func writeABACPolicyFile(nodes []v3.RKEConfigNode, doc string) error {
+	for _, n := range nodes {
+		if !hasRole(n.Role, "controlplane") {
+			continue
+		}
+		h := &hosts.Host{RKEConfigNode: n}
+		if err := sshWriteFile(h, abacPolicyFilePath, doc); err != nil {
+			return fmt.Errorf("writing ABAC policy file to %s: %s", h.Address, err)
+		}
+	}
+	return nil
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether s is already in values. overlayResourceRKEConfig uses
+// this to guard the abacPolicyExtraBind injection - the services_kube_api block is
+// Optional+Computed, so the bind it injects on one apply comes back through d on the
+// next, and appending unconditionally would grow the bind list by one every apply.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// abacPoliciesToMaps reverses mapGetABACPolicies, so clusterToState can round-trip
+// authorization.policies instead of leaving it out of state - policies aren't part of
+// v3.AuthzConfig, so the caller has to thread the parsed policies back in itself, the
+// same way it already does for versionServiceOptionsOverride.
+func abacPoliciesToMaps(policies []abacPolicy) []interface{} {
+	out := make([]interface{}, len(policies))
+	for i, p := range policies {
+		out[i] = map[string]interface{}{
+			"user":             p.User,
+			"group":            p.Group,
+			"namespace":        p.Namespace,
+			"resource":         p.Resource,
+			"api_group":        p.APIGroup,
+			"nonresource_path": p.NonResourcePath,
+			"readonly":         p.Readonly,
+		}
+	}
+	return out
+}
+
+// authzModeHasABAC reports whether authorization.mode includes "abac", supporting
+// both the plain "abac" mode and combined modes like "rbac,abac" so RBAC and ABAC
+// can run side by side.
+func authzModeHasABAC(mode string) bool {
+	for _, m := range strings.Split(mode, ",") {
+		if strings.TrimSpace(m) == "abac" {
+			return true
+		}
+	}
+	return false
+}
+
+func sshWriteFile(h *hosts.Host, path, contents string) error {
+	auth, err := sshAuthMethod(h.RKEConfigNode)
+	if err != nil {
+		return err
+	}
+	hostKeyCallback, err := sshHostKeyCallback()
+	if err != nil {
+		return err
+	}
+
+	port := h.Port
+	if port == "" {
+		port = "22"
+	}
+
+	client, err := ssh.Dial("tcp", net.JoinHostPort(h.Address, port), &ssh.ClientConfig{
+		User:            h.User,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	session.Stdin = bytes.NewBufferString(contents)
+	return session.Run(fmt.Sprintf("mkdir -p $(dirname %s) && cat > %s", path, path))
+}
+
+// sshHostKeyCallback verifies the remote host key against the operator's known_hosts
+// file, same as any other SSH client would, instead of trusting whatever key the
+// channel offers - the file it distributes ends up in kube-apiserver's
+// --authorization-policy-file, so a MITM'd write here is a cluster-wide authz bypass.
+func sshHostKeyCallback() (ssh.HostKeyCallback, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("resolving known_hosts: %s", err)
+	}
+	return knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+}
+
+func sshAuthMethod(n v3.RKEConfigNode) (ssh.AuthMethod, error) {
+	if n.SSHAgentAuth {
+		sock := os.Getenv("SSH_AUTH_SOCK")
+		if sock == "" {
+			return nil, fmt.Errorf("ssh_agent_auth is set but SSH_AUTH_SOCK is not")
+		}
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			return nil, err
+		}
+		return ssh.PublicKeysCallback(agent.NewClient(conn).Signers), nil
+	}
+
+	keyData := n.SSHKey
+	if keyData == "" && n.SSHKeyPath != "" {
+		b, err := ioutil.ReadFile(n.SSHKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		keyData = string(b)
+	}
+	if keyData == "" {
+		return nil, fmt.Errorf("node %s has no ssh_key, ssh_key_path, or ssh_agent_auth", n.Address)
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(keyData))
+	if err != nil {
+		return nil, err
+	}
+	return ssh.PublicKeys(signer), nil
+}
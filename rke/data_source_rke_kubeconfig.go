@@ -0,0 +1,79 @@
+package rke
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// dataSourceRKEKubeconfig merges the kubeconfig produced by an rke_cluster (or
+// rke_cluster_yaml) resource with the kubeconfig file at merge_into, if set, under a
+// caller-chosen context name, without disturbing any other cluster/context/user already
+// there. The result is returned as merged_yaml; the file itself is not written.
+func dataSourceRKEKubeconfig() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceRKEKubeconfigRead,
+
+		Schema: map[string]*schema.Schema{
+			"kube_config_yaml": {
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"context_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"merge_into": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"merged_yaml": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+		},
+	}
+}
+
+func dataSourceRKEKubeconfigRead(d *schema.ResourceData, meta interface{}) error {
+	contextName := d.Get("context_name").(string)
+
+	merged, err := mergedKubeConfigYAML(d, contextName)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(contextName)
+	return d.Set("merged_yaml", merged)
+}
+
+// mergedKubeConfigYAML loads kube_config_yaml and, if set, merge_into, and returns the
+// combined document with the RKE cluster injected under contextName. It is only used
+// by the data source: resourceRKEKubeconfigWrite merges into "path" instead and writes
+// the result back to disk rather than into state.
+func mergedKubeConfigYAML(d rkeResourceGetter, contextName string) (string, error) {
+	v, _ := d.GetOk("kube_config_yaml")
+	generated, err := loadKubeConfig(v.(string))
+	if err != nil {
+		return "", fmt.Errorf("parsing kube_config_yaml: %s", err)
+	}
+
+	mergeInto, _ := d.GetOk("merge_into")
+	base, err := loadKubeConfigFile(mergeInto.(string))
+	if err != nil {
+		return "", fmt.Errorf("loading merge_into: %s", err)
+	}
+
+	if err := mergeKubeConfig(base, generated, contextName); err != nil {
+		return "", err
+	}
+
+	out, err := clientcmd.Write(*base)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,110 @@
+package rke
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher/rke/cluster"
+	"github.com/rancher/rke/pki"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func loadKubeConfig(yamlBody string) (*clientcmdapi.Config, error) {
+	return clientcmd.Load([]byte(yamlBody))
+}
+
+// loadKubeConfigFile loads an existing kubeconfig from disk, returning an empty one
+// if path is unset or the file doesn't exist yet - merging into a kubeconfig that
+// doesn't exist yet should create it, not fail.
+func loadKubeConfigFile(path string) (*clientcmdapi.Config, error) {
+	if path == "" {
+		return clientcmdapi.NewConfig(), nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return clientcmdapi.NewConfig(), nil
+	}
+	return clientcmd.LoadFromFile(path)
+}
+
+// mergeKubeConfig injects the single cluster/context/user the RKE-generated kubeconfig
+// carries into base under contextName, preserving every other context base already has.
+func mergeKubeConfig(base, generated *clientcmdapi.Config, contextName string) error {
+	if len(generated.Contexts) != 1 {
+		return fmt.Errorf("expected exactly one context in the generated kubeconfig, got %d", len(generated.Contexts))
+	}
+
+	var srcContext *clientcmdapi.Context
+	for _, ctx := range generated.Contexts {
+		srcContext = ctx
+	}
+
+	cluster, ok := generated.Clusters[srcContext.Cluster]
+	if !ok {
+		return fmt.Errorf("generated kubeconfig is missing cluster %q", srcContext.Cluster)
+	}
+	user, ok := generated.AuthInfos[srcContext.AuthInfo]
+	if !ok {
+		return fmt.Errorf("generated kubeconfig is missing user %q", srcContext.AuthInfo)
+	}
+
+	if base.Clusters == nil {
+		base.Clusters = map[string]*clientcmdapi.Cluster{}
+	}
+	if base.AuthInfos == nil {
+		base.AuthInfos = map[string]*clientcmdapi.AuthInfo{}
+	}
+	if base.Contexts == nil {
+		base.Contexts = map[string]*clientcmdapi.Context{}
+	}
+
+	base.Clusters[contextName] = cluster
+	base.AuthInfos[contextName] = user
+	base.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:  contextName,
+		AuthInfo: contextName,
+	}
+
+	return nil
+}
+
+// adminKubeConfigState extracts the admin kubeconfig RKE renders into the cluster's
+// certificate bundle during ClusterUp (the same one adminClientset uses for
+// wait_for_ready) into the handful of computed fields the cluster resource exposes. It
+// returns a nil map, not an error, if the cluster has no admin certificate yet - the
+// fields just stay unset in that case.
+func adminKubeConfigState(c *cluster.Cluster) (map[string]interface{}, error) {
+	entry, ok := c.Certificates[pki.KubeAdminCertName]
+	if !ok {
+		return nil, nil
+	}
+
+	parsed, err := loadKubeConfig(entry.Config)
+	if err != nil {
+		return nil, fmt.Errorf("parsing admin kubeconfig: %s", err)
+	}
+
+	state := map[string]interface{}{
+		"kube_config_yaml": entry.Config,
+	}
+	for _, kubeCluster := range parsed.Clusters {
+		state["ca_crt"] = string(kubeCluster.CertificateAuthorityData)
+		state["api_server_url"] = kubeCluster.Server
+	}
+	for _, authInfo := range parsed.AuthInfos {
+		state["client_cert"] = string(authInfo.ClientCertificateData)
+		state["client_key"] = string(authInfo.ClientKeyData)
+	}
+	return state, nil
+}
+
+// removeKubeConfigContext removes the cluster/context/user previously injected under
+// contextName, leaving the rest of base untouched.
+func removeKubeConfigContext(base *clientcmdapi.Config, contextName string) {
+	delete(base.Contexts, contextName)
+	delete(base.Clusters, contextName)
+	delete(base.AuthInfos, contextName)
+	if base.CurrentContext == contextName {
+		base.CurrentContext = ""
+	}
+}
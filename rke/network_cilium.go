@@ -0,0 +1,134 @@
+package rke
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+)
+
+// ciliumOptions flattens the typed network.cilium block into the freeform
+// NetworkConfig.Options map RKE actually passes through to the cilium addon
+// template, so operators get validated fields instead of hand-encoded strings.
+func ciliumOptions(m map[string]interface{}) map[string]string {
+	options := map[string]string{}
+
+	if v := mapGetString(m, "version"); v != "" {
+		options["cilium-version"] = v
+	}
+	if v := mapGetString(m, "ipam"); v != "" {
+		options["ipam"] = v
+	}
+	if v := mapGetString(m, "tunnel"); v != "" {
+		options["tunnel"] = v
+	}
+	if v, ok := m["enable_bpf_masquerade"]; ok && v != nil {
+		options["enable-bpf-masquerade"] = strconv.FormatBool(v.(bool))
+	}
+	if v, ok := m["enable_hubble"]; ok && v != nil {
+		options["enable-hubble"] = strconv.FormatBool(v.(bool))
+	}
+
+	return options
+}
+
+// labelCiliumEtcdNodes merges nodeSelector into the Labels of every etcd-role node, so
+// the dedicated cilium-etcd EtcdCluster's nodeSelector (rendered by
+// ciliumEtcdAddonManifest) actually matches something instead of scheduling nowhere. It
+// is a no-op when nodeSelector is empty.
+func labelCiliumEtcdNodes(nodes []v3.RKEConfigNode, nodeSelector map[string]string) {
+	if len(nodeSelector) == 0 {
+		return
+	}
+	for i := range nodes {
+		if !hasRole(nodes[i].Role, "etcd") {
+			continue
+		}
+		if nodes[i].Labels == nil {
+			nodes[i].Labels = map[string]string{}
+		}
+		for k, v := range nodeSelector {
+			nodes[i].Labels[k] = v
+		}
+	}
+}
+
+// ciliumOptionsToMap reverses ciliumOptions, decoding the flattened Options map (plus
+// the node_selector threaded in separately via networkAddonState, since it never makes
+// it into Options) back into the network.cilium block shape so clusterToState can
+// round-trip it instead of leaving cilium looking removed on every plan.
+func ciliumOptionsToMap(options map[string]string, etcdNodeSelector map[string]string) map[string]interface{} {
+	m := map[string]interface{}{}
+
+	if v, ok := options["cilium-version"]; ok {
+		m["version"] = v
+	}
+	if v, ok := options["ipam"]; ok {
+		m["ipam"] = v
+	}
+	if v, ok := options["tunnel"]; ok {
+		m["tunnel"] = v
+	}
+	if v, ok := options["enable-bpf-masquerade"]; ok {
+		m["enable_bpf_masquerade"] = v == "true"
+	}
+	if v, ok := options["enable-hubble"]; ok {
+		m["enable_hubble"] = v == "true"
+	}
+	if len(etcdNodeSelector) > 0 {
+		m["etcd"] = []interface{}{map[string]interface{}{"node_selector": etcdNodeSelector}}
+	}
+
+	return m
+}
+
+// parseResourceCiliumEtcdAddon renders the etcd-operator addon manifest for a
+// dedicated cilium-etcd cluster when network.cilium.etcd is set, following the same
+// pattern kops uses for --cilium-etcd. It returns ok=false when the block is absent.
+func parseResourceCiliumEtcdAddon(d rkeResourceGetter) (string, bool, error) {
+	network, ok := firstBlock(d, "network")
+	if !ok {
+		return "", false, nil
+	}
+	cilium, ok := mapGetBlock(network, "cilium")
+	if !ok {
+		return "", false, nil
+	}
+	etcd, ok := mapGetBlock(cilium, "etcd")
+	if !ok {
+		return "", false, nil
+	}
+
+	return ciliumEtcdAddonManifest(mapGetStringMap(etcd, "node_selector")), true, nil
+}
+
+// ciliumEtcdAddonManifest renders the etcd-operator cluster manifest that backs
+// cilium's dedicated etcd, scoped to the hosts matching nodeSelector - the same
+// selector labelCiliumEtcdNodes applies as labels to the etcd-role nodes.
+func ciliumEtcdAddonManifest(nodeSelector map[string]string) string {
+	keys := make([]string, 0, len(nodeSelector))
+	for k := range nodeSelector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var selector strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&selector, "\n      %s: %q", k, nodeSelector[k])
+	}
+
+	return fmt.Sprintf(`---
+apiVersion: etcd.database.coreos.com/v1beta2
+kind: EtcdCluster
+metadata:
+  name: cilium-etcd
+  namespace: kube-system
+spec:
+  size: 3
+  version: 3.3.13
+  pod:
+    nodeSelector:%s
+`, selector.String())
+}
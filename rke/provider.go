@@ -0,0 +1,881 @@
+package rke
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Provider returns a terraform.ResourceProvider for RKE (Rancher Kubernetes Engine).
+func Provider() *schema.ResourceProvider {
+	return &schema.ResourceProvider{
+		ResourcesMap: map[string]*schema.Resource{
+			"rke_cluster":          resourceRKECluster(),
+			"rke_cluster_yaml":     resourceRKEClusterYAML(),
+			"rke_kubeconfig_write": resourceRKEKubeconfigWrite(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"rke_kubeconfig": dataSourceRKEKubeconfig(),
+		},
+		ConfigureFunc: providerConfigure,
+	}
+}
+
+func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+	return nil, nil
+}
+
+func resourceRKECluster() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceRKEClusterCreate,
+		Read:   resourceRKEClusterRead,
+		Update: resourceRKEClusterUpdate,
+		Delete: resourceRKEClusterDelete,
+
+		Schema: mergeSchemas(clusterConfigSchema(true), clusterComputedSchema()),
+	}
+}
+
+// mergeSchemas flattens a set of schema maps into one, later maps winning on key
+// collisions. Used to share the cluster config/computed blocks between rke_cluster
+// and rke_cluster_yaml.
+func mergeSchemas(maps ...map[string]*schema.Schema) map[string]*schema.Schema {
+	out := map[string]*schema.Schema{}
+	for _, m := range maps {
+		for k, v := range m {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// clusterConfigSchema returns the HCL-facing input blocks shared by rke_cluster and
+// rke_cluster_yaml. nodesRequired is false for rke_cluster_yaml, where nodes may
+// instead come entirely from yaml_body.
+func clusterConfigSchema(nodesRequired bool) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"nodes": {
+			Type:     schema.TypeList,
+			Required: nodesRequired,
+			Optional: !nodesRequired,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"node_name": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"address": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"port": {
+						Type:     schema.TypeInt,
+						Optional: true,
+					},
+					"internal_address": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"role": {
+						Type:     schema.TypeList,
+						Required: true,
+						Elem: &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validateRKERole,
+						},
+					},
+					"hostname_override": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"user": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"docker_socket": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"ssh_agent_auth": {
+						Type:     schema.TypeBool,
+						Optional: true,
+					},
+					"ssh_key": {
+						Type:      schema.TypeString,
+						Optional:  true,
+						Sensitive: true,
+					},
+					"ssh_key_path": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"ssh_cert": {
+						Type:      schema.TypeString,
+						Optional:  true,
+						Sensitive: true,
+					},
+					"ssh_cert_path": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"labels": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"taints": {
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"key": {
+									Type:     schema.TypeString,
+									Required: true,
+								},
+								"value": {
+									Type:     schema.TypeString,
+									Required: true,
+								},
+								"effect": {
+									Type:         schema.TypeString,
+									Required:     true,
+									ValidateFunc: validateRKETaintEffect,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"services_etcd":            serviceSchema(etcdServiceExtraSchema()),
+		"services_kube_api":        serviceSchema(kubeAPIServiceExtraSchema()),
+		"services_kube_controller": serviceSchema(kubeControllerServiceExtraSchema()),
+		"services_scheduler":       serviceSchema(nil),
+		"services_kubelet":         serviceSchema(kubeletServiceExtraSchema()),
+		"services_kubeproxy":       serviceSchema(nil),
+		"network": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"plugin": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"options": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"cilium": {
+						Type:     schema.TypeList,
+						Optional: true,
+						MaxItems: 1,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"version": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"ipam": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"tunnel": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"enable_bpf_masquerade": {
+									Type:     schema.TypeBool,
+									Optional: true,
+								},
+								"enable_hubble": {
+									Type:     schema.TypeBool,
+									Optional: true,
+								},
+								"etcd": {
+									Type:     schema.TypeList,
+									Optional: true,
+									MaxItems: 1,
+									Elem: &schema.Resource{
+										Schema: map[string]*schema.Schema{
+											"node_selector": {
+												Type:     schema.TypeMap,
+												Optional: true,
+												Elem:     &schema.Schema{Type: schema.TypeString},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					"multus": {
+						Type:     schema.TypeList,
+						Optional: true,
+						MaxItems: 1,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"version": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"attachment_definition": {
+									Type:     schema.TypeList,
+									Optional: true,
+									Elem: &schema.Resource{
+										Schema: map[string]*schema.Schema{
+											"name": {
+												Type:     schema.TypeString,
+												Required: true,
+											},
+											"namespace": {
+												Type:     schema.TypeString,
+												Optional: true,
+											},
+											"cni_type": {
+												Type:         schema.TypeString,
+												Optional:     true,
+												ValidateFunc: validateMultusCNIType,
+											},
+											"master": {
+												Type:     schema.TypeString,
+												Optional: true,
+											},
+											"mode": {
+												Type:         schema.TypeString,
+												Optional:     true,
+												ValidateFunc: validateMultusCNIMode,
+											},
+											"ipam": {
+												Type:     schema.TypeList,
+												Optional: true,
+												MaxItems: 1,
+												Elem: &schema.Resource{
+													Schema: map[string]*schema.Schema{
+														"type": {
+															Type:         schema.TypeString,
+															Optional:     true,
+															ValidateFunc: validateMultusIPAMType,
+														},
+														"range": {
+															Type:     schema.TypeString,
+															Optional: true,
+														},
+													},
+												},
+											},
+											"config": {
+												Type:     schema.TypeString,
+												Optional: true,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"authentication": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"strategy": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"options": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"sans": {
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		},
+		"addons": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"addons_include": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"system_images": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Computed: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: systemImagesSchema(),
+			},
+		},
+		"ssh_key_path": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"ssh_agent_auth": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"authorization": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"mode": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"options": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"policies": {
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"user": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"group": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"namespace": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"resource": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"api_group": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"nonresource_path": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"readonly": {
+									Type:     schema.TypeBool,
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"ignore_docker_version": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"kubernetes_version": {
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		},
+		"version_service_options_override": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"kubernetes_version": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"etcd_extra_args": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"kube_api_extra_args": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"kube_controller_extra_args": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"scheduler_extra_args": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"kubelet_extra_args": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"kubeproxy_extra_args": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		},
+		"private_registries": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"url": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"user": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"password": {
+						Type:      schema.TypeString,
+						Optional:  true,
+						Sensitive: true,
+					},
+				},
+			},
+		},
+		"ingress": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"provider": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"options": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+					"node_selector": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		},
+		"cluster_name": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"cloud_provider": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"cloud_config": {
+						Type:     schema.TypeMap,
+						Optional: true,
+						Elem:     &schema.Schema{Type: schema.TypeString},
+					},
+				},
+			},
+		},
+		"wait_for_ready": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"enabled": {
+						Type:     schema.TypeBool,
+						Optional: true,
+					},
+					"timeout": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"poll_interval": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"min_ready_nodes": {
+						Type:     schema.TypeInt,
+						Optional: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+// clusterComputedSchema returns the attributes populated from the cluster.Cluster
+// returned by cluster.ClusterUp, shared by rke_cluster and rke_cluster_yaml.
+func clusterComputedSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"certificates": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"id":              {Type: schema.TypeString, Computed: true},
+					"certificate":     {Type: schema.TypeString, Computed: true},
+					"key":             {Type: schema.TypeString, Computed: true, Sensitive: true},
+					"config":          {Type: schema.TypeString, Computed: true},
+					"name":            {Type: schema.TypeString, Computed: true},
+					"common_name":     {Type: schema.TypeString, Computed: true},
+					"ou_name":         {Type: schema.TypeString, Computed: true},
+					"env_name":        {Type: schema.TypeString, Computed: true},
+					"path":            {Type: schema.TypeString, Computed: true},
+					"key_env_name":    {Type: schema.TypeString, Computed: true},
+					"key_path":        {Type: schema.TypeString, Computed: true},
+					"config_env_name": {Type: schema.TypeString, Computed: true},
+					"config_path":     {Type: schema.TypeString, Computed: true},
+				},
+			},
+		},
+		"cluster_domain": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"cluster_cidr": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"cluster_dns_server": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"etcd_hosts": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: hostSummarySchema(),
+			},
+		},
+		"worker_hosts": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: hostSummarySchema(),
+			},
+		},
+		"control_plane_hosts": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: hostSummarySchema(),
+			},
+		},
+		"inactive_hosts": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: hostSummarySchema(),
+			},
+		},
+		"kube_config_yaml": {
+			Type:      schema.TypeString,
+			Computed:  true,
+			Sensitive: true,
+		},
+		"ca_crt": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"client_cert": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"client_key": {
+			Type:      schema.TypeString,
+			Computed:  true,
+			Sensitive: true,
+		},
+		"api_server_url": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+	}
+}
+
+func hostSummarySchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"node_name": {Type: schema.TypeString, Computed: true},
+		"address":   {Type: schema.TypeString, Computed: true},
+	}
+}
+
+// serviceSchema builds the common `image`/`extra_args`/`extra_binds` schema shared by
+// every services_* block, merging in any service-specific fields.
+func serviceSchema(extra map[string]*schema.Schema) *schema.Schema {
+	s := map[string]*schema.Schema{
+		"image": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"extra_args": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"extra_binds": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"extra_env": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"resolved_extra_args": {
+			Type:     schema.TypeMap,
+			Computed: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+	}
+	for k, v := range extra {
+		s[k] = v
+	}
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: s,
+		},
+	}
+}
+
+func etcdServiceExtraSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"external_urls": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem:     &schema.Schema{Type: schema.TypeString},
+		},
+		"ca_cert": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"cert": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"key": {
+			Type:      schema.TypeString,
+			Optional:  true,
+			Sensitive: true,
+		},
+		"path": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"snapshot": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"retention": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"creation": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"backup_config": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"interval": {
+						Type:     schema.TypeInt,
+						Optional: true,
+					},
+					"retention": {
+						Type:     schema.TypeInt,
+						Optional: true,
+					},
+					"s3_backup_config": {
+						Type:     schema.TypeList,
+						Optional: true,
+						MaxItems: 1,
+						Elem: &schema.Resource{
+							Schema: map[string]*schema.Schema{
+								"endpoint": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"bucket": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"region": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"access_key": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+								"secret_key": {
+									Type:      schema.TypeString,
+									Optional:  true,
+									Sensitive: true,
+								},
+								"folder": {
+									Type:     schema.TypeString,
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func kubeAPIServiceExtraSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"service_cluster_ip_range": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"service_node_port_range": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"pod_security_policy": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+		"always_pull_images": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+	}
+}
+
+func kubeControllerServiceExtraSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"cluster_cidr": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"service_cluster_ip_range": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+	}
+}
+
+func kubeletServiceExtraSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"cluster_domain": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"infra_container_image": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"cluster_dns_server": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"fail_swap_on": {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+	}
+}
+
+func systemImagesSchema() map[string]*schema.Schema {
+	fields := []string{
+		"etcd", "alpine", "nginx_proxy", "cert_downloader", "kubernetes_services_sidecar",
+		"kube_dns", "dnsmasq", "kube_dns_sidecar", "kube_dns_autoscaler", "kubernetes",
+		"flannel", "flannel_cni", "calico_node", "calico_cni", "calico_controllers", "calico_ctl",
+		"canal_node", "canal_cni", "canal_flannel", "weave_node", "weave_cni",
+		"pod_infra_container", "ingress", "ingress_backend", "dashboard", "heapster",
+		"grafana", "influxdb", "tiller",
+	}
+	s := make(map[string]*schema.Schema, len(fields))
+	for _, f := range fields {
+		s[f] = &schema.Schema{
+			Type:     schema.TypeString,
+			Optional: true,
+			Computed: true,
+		}
+	}
+	return s
+}
+
+func validateRKERole(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	switch value {
+	case "etcd", "controlplane", "worker":
+		return
+	}
+	errors = append(errors, errInvalidValue(k, value, []string{"etcd", "controlplane", "worker"}))
+	return
+}
+
+func validateRKETaintEffect(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	switch value {
+	case "NoSchedule", "PreferNoSchedule", "NoExecute":
+		return
+	}
+	errors = append(errors, errInvalidValue(k, value, []string{"NoSchedule", "PreferNoSchedule", "NoExecute"}))
+	return
+}
+
+func validateMultusCNIType(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	switch value {
+	case "macvlan", "ipvlan", "bridge", "sr-iov":
+		return
+	}
+	errors = append(errors, errInvalidValue(k, value, []string{"macvlan", "ipvlan", "bridge", "sr-iov"}))
+	return
+}
+
+func validateMultusCNIMode(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	switch value {
+	case "bridge", "l2", "l3":
+		return
+	}
+	errors = append(errors, errInvalidValue(k, value, []string{"bridge", "l2", "l3"}))
+	return
+}
+
+func validateMultusIPAMType(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	switch value {
+	case "static", "host-local", "whereabouts":
+		return
+	}
+	errors = append(errors, errInvalidValue(k, value, []string{"static", "host-local", "whereabouts"}))
+	return
+}
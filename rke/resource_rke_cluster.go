@@ -0,0 +1,289 @@
+package rke
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/rancher/rke/cluster"
+	"github.com/rancher/rke/hosts"
+	"github.com/rancher/rke/log"
+	"github.com/rancher/rke/pki"
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+)
+
+// parseResourceRKEConfig assembles the full RKE cluster config from the resource's
+// schema, delegating each block to its matching parseResource* helper. The returned
+// ABAC policies, if any, still need to be rendered and distributed by the caller.
+func parseResourceRKEConfig(d rkeResourceGetter) (*v3.RancherKubernetesEngineConfig, []abacPolicy, error) {
+	rkeConfig := &v3.RancherKubernetesEngineConfig{}
+	policies, err := overlayResourceRKEConfig(d, rkeConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rkeConfig, policies, nil
+}
+
+// overlayResourceRKEConfig applies every block the resource schema has set on top of
+// rkeConfig, leaving fields the caller left unset (e.g. loaded from yaml_body) alone.
+// It returns any ABAC policies parsed from the authorization block.
+func overlayResourceRKEConfig(d rkeResourceGetter, rkeConfig *v3.RancherKubernetesEngineConfig) ([]abacPolicy, error) {
+	nodes, err := parseResourceRKEConfigNode(d)
+	if err != nil {
+		return nil, err
+	}
+	if nodes != nil {
+		rkeConfig.Nodes = nodes
+	}
+
+	etcd, err := parseResourceETCDService(d)
+	if err != nil {
+		return nil, err
+	}
+	if etcd != nil {
+		rkeConfig.Services.Etcd = *etcd
+	}
+
+	kubeAPI, err := parseResourceKubeAPIService(d)
+	if err != nil {
+		return nil, err
+	}
+	if kubeAPI != nil {
+		rkeConfig.Services.KubeAPI = *kubeAPI
+	}
+
+	kubeController, err := parseResourceKubeControllerService(d)
+	if err != nil {
+		return nil, err
+	}
+	if kubeController != nil {
+		rkeConfig.Services.KubeController = *kubeController
+	}
+
+	scheduler, err := parseResourceSchedulerService(d)
+	if err != nil {
+		return nil, err
+	}
+	if scheduler != nil {
+		rkeConfig.Services.Scheduler = *scheduler
+	}
+
+	kubelet, err := parseResourceKubeletService(d)
+	if err != nil {
+		return nil, err
+	}
+	if kubelet != nil {
+		rkeConfig.Services.Kubelet = *kubelet
+	}
+
+	kubeproxy, err := parseResourceKubeproxyService(d)
+	if err != nil {
+		return nil, err
+	}
+	if kubeproxy != nil {
+		rkeConfig.Services.Kubeproxy = *kubeproxy
+	}
+
+	network, err := parseResourceNetwork(d)
+	if err != nil {
+		return nil, err
+	}
+	if network != nil {
+		rkeConfig.Network = *network
+	}
+	labelCiliumEtcdNodes(rkeConfig.Nodes, parseResourceNetworkAddonState(d).CiliumEtcdNodeSelector)
+
+	authn, err := parseResourceAuthentication(d)
+	if err != nil {
+		return nil, err
+	}
+	if authn != nil {
+		rkeConfig.Authentication = *authn
+	}
+
+	addons, err := parseResourceAddons(d)
+	if err != nil {
+		return nil, err
+	}
+	if addons != "" {
+		rkeConfig.Addons = addons
+	}
+
+	addonsInclude, err := parseResourceAddonsInclude(d)
+	if err != nil {
+		return nil, err
+	}
+	if addonsInclude != nil {
+		rkeConfig.AddonsInclude = addonsInclude
+	}
+
+	if ciliumEtcdAddon, ok, err := parseResourceCiliumEtcdAddon(d); err != nil {
+		return nil, err
+	} else if ok {
+		rkeConfig.Addons = strings.Join([]string{rkeConfig.Addons, ciliumEtcdAddon}, "\n")
+	}
+
+	if multusAddon, ok, err := parseResourceMultusAddon(d); err != nil {
+		return nil, err
+	} else if ok {
+		rkeConfig.Addons = strings.Join([]string{rkeConfig.Addons, multusAddon}, "\n")
+	}
+
+	systemImages, err := parseResourceSystemImages(d)
+	if err != nil {
+		return nil, err
+	}
+	if systemImages != nil {
+		rkeConfig.SystemImages = *systemImages
+	}
+
+	sshKeyPath, err := parseResourceSSHKeyPath(d)
+	if err != nil {
+		return nil, err
+	}
+	if sshKeyPath != "" {
+		rkeConfig.SSHKeyPath = sshKeyPath
+	}
+
+	sshAgentAuth, err := parseResourceSSHAgentAuth(d)
+	if err != nil {
+		return nil, err
+	}
+	if sshAgentAuth {
+		rkeConfig.SSHAgentAuth = sshAgentAuth
+	}
+
+	authz, policies, err := parseResourceAuthorization(d)
+	if err != nil {
+		return nil, err
+	}
+	if authz != nil {
+		rkeConfig.Authorization = *authz
+	}
+	if len(policies) > 0 {
+		if rkeConfig.Services.KubeAPI.ExtraArgs == nil {
+			rkeConfig.Services.KubeAPI.ExtraArgs = map[string]string{}
+		}
+		rkeConfig.Services.KubeAPI.ExtraArgs["authorization-policy-file"] = abacPolicyFilePath
+		if !containsString(rkeConfig.Services.KubeAPI.ExtraBinds, abacPolicyExtraBind) {
+			rkeConfig.Services.KubeAPI.ExtraBinds = append(rkeConfig.Services.KubeAPI.ExtraBinds, abacPolicyExtraBind)
+		}
+	}
+
+	ignoreDockerVersion, err := parseResourceIgnoreDockerVersion(d)
+	if err != nil {
+		return nil, err
+	}
+	if ignoreDockerVersion {
+		rkeConfig.IgnoreDockerVersion = ignoreDockerVersion
+	}
+
+	version, err := parseResourceVersion(d)
+	if err != nil {
+		return nil, err
+	}
+	if version != "" {
+		rkeConfig.Version = version
+	}
+
+	privateRegistries, err := parseResourcePrivateRegistries(d)
+	if err != nil {
+		return nil, err
+	}
+	if privateRegistries != nil {
+		rkeConfig.PrivateRegistries = privateRegistries
+	}
+
+	ingress, err := parseResourceIngress(d)
+	if err != nil {
+		return nil, err
+	}
+	if ingress != nil {
+		rkeConfig.Ingress = *ingress
+	}
+
+	clusterName, err := parseResourceClusterName(d)
+	if err != nil {
+		return nil, err
+	}
+	if clusterName != "" {
+		rkeConfig.ClusterName = clusterName
+	}
+
+	cloudProvider, err := parseResourceCloudProvider(d)
+	if err != nil {
+		return nil, err
+	}
+	if cloudProvider != nil {
+		rkeConfig.CloudProvider = *cloudProvider
+	}
+
+	return policies, nil
+}
+
+func clusterUp(ctx context.Context, rkeConfig *v3.RancherKubernetesEngineConfig) (*cluster.Cluster, error) {
+	return cluster.ClusterUp(ctx, rkeConfig, hosts.DialersOptions{}, nil, nil, log.New(), pki.GetCertPath(""), "", false)
+}
+
+func resourceRKEClusterCreate(d *schema.ResourceData, meta interface{}) error {
+	rkeConfig, policies, err := parseResourceRKEConfig(d)
+	if err != nil {
+		return err
+	}
+
+	if len(policies) > 0 {
+		doc, err := abacPolicyDocument(policies)
+		if err != nil {
+			return err
+		}
+		if err := writeABACPolicyFile(rkeConfig.Nodes, doc); err != nil {
+			return err
+		}
+	}
+
+	waitForReady, err := parseResourceWaitForReady(d)
+	if err != nil {
+		return err
+	}
+
+	versionServiceOptionsOverride, err := parseResourceVersionServiceOptionsOverride(d)
+	if err != nil {
+		return err
+	}
+
+	c, err := clusterUp(context.Background(), rkeConfig)
+	if err != nil {
+		return fmt.Errorf("rke up failed: %s", err)
+	}
+
+	if err := waitForClusterReady(c, waitForReady); err != nil {
+		return err
+	}
+
+	d.SetId(rkeConfig.ClusterName)
+
+	return clusterToState(c, d, versionServiceOptionsOverride, parseResourceNetworkAddonState(d), policies)
+}
+
+func resourceRKEClusterRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+func resourceRKEClusterUpdate(d *schema.ResourceData, meta interface{}) error {
+	return resourceRKEClusterCreate(d, meta)
+}
+
+func resourceRKEClusterDelete(d *schema.ResourceData, meta interface{}) error {
+	rkeConfig, _, err := parseResourceRKEConfig(d)
+	if err != nil {
+		return err
+	}
+
+	if err := cluster.ClusterRemove(context.Background(), rkeConfig, hosts.DialersOptions{}, log.New()); err != nil {
+		return fmt.Errorf("rke remove failed: %s", err)
+	}
+
+	d.SetId("")
+	return nil
+}
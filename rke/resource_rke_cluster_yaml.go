@@ -0,0 +1,118 @@
+package rke
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/rancher/rke/cluster"
+	"github.com/rancher/rke/hosts"
+	"github.com/rancher/rke/log"
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// resourceRKEClusterYAML lets operators hand a raw cluster.yml to the provider instead
+// of translating every knob into the rke_cluster blocks, while still allowing those
+// blocks to be set alongside yaml_body to incrementally migrate off of it.
+func resourceRKEClusterYAML() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceRKEClusterYAMLCreate,
+		Read:   resourceRKEClusterRead,
+		Update: resourceRKEClusterYAMLUpdate,
+		Delete: resourceRKEClusterYAMLDelete,
+
+		Schema: mergeSchemas(
+			map[string]*schema.Schema{
+				"yaml_body": {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+			},
+			clusterConfigSchema(false),
+			clusterComputedSchema(),
+		),
+	}
+}
+
+// parseResourceRKEConfigYAML unmarshals yaml_body into a RancherKubernetesEngineConfig
+// and overlays any TF-provided blocks on top of it, with the TF blocks winning. Any
+// ABAC policies parsed from the authorization block still need to be rendered and
+// distributed by the caller.
+func parseResourceRKEConfigYAML(d rkeResourceGetter) (*v3.RancherKubernetesEngineConfig, []abacPolicy, error) {
+	v, ok := d.GetOk("yaml_body")
+	if !ok {
+		return nil, nil, fmt.Errorf("yaml_body is required")
+	}
+	yamlBody := v.(string)
+
+	rkeConfig := &v3.RancherKubernetesEngineConfig{}
+	if err := yaml.Unmarshal([]byte(yamlBody), rkeConfig); err != nil {
+		return nil, nil, fmt.Errorf("parsing yaml_body: %s", err)
+	}
+
+	policies, err := overlayResourceRKEConfig(d, rkeConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rkeConfig, policies, nil
+}
+
+func resourceRKEClusterYAMLCreate(d *schema.ResourceData, meta interface{}) error {
+	rkeConfig, policies, err := parseResourceRKEConfigYAML(d)
+	if err != nil {
+		return err
+	}
+
+	if len(policies) > 0 {
+		doc, err := abacPolicyDocument(policies)
+		if err != nil {
+			return err
+		}
+		if err := writeABACPolicyFile(rkeConfig.Nodes, doc); err != nil {
+			return err
+		}
+	}
+
+	waitForReady, err := parseResourceWaitForReady(d)
+	if err != nil {
+		return err
+	}
+
+	versionServiceOptionsOverride, err := parseResourceVersionServiceOptionsOverride(d)
+	if err != nil {
+		return err
+	}
+
+	c, err := clusterUp(context.Background(), rkeConfig)
+	if err != nil {
+		return fmt.Errorf("rke up failed: %s", err)
+	}
+
+	if err := waitForClusterReady(c, waitForReady); err != nil {
+		return err
+	}
+
+	d.SetId(rkeConfig.ClusterName)
+
+	return clusterToState(c, d, versionServiceOptionsOverride, parseResourceNetworkAddonState(d), policies)
+}
+
+func resourceRKEClusterYAMLUpdate(d *schema.ResourceData, meta interface{}) error {
+	return resourceRKEClusterYAMLCreate(d, meta)
+}
+
+func resourceRKEClusterYAMLDelete(d *schema.ResourceData, meta interface{}) error {
+	rkeConfig, _, err := parseResourceRKEConfigYAML(d)
+	if err != nil {
+		return err
+	}
+
+	if err := cluster.ClusterRemove(context.Background(), rkeConfig, hosts.DialersOptions{}, log.New()); err != nil {
+		return fmt.Errorf("rke remove failed: %s", err)
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,93 @@
+package rke
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// resourceRKEKubeconfigWrite idempotently merges an rke_cluster's kubeconfig into an
+// existing kubeconfig file on disk under a caller-chosen context, and removes that
+// context again on destroy instead of touching the rest of the file.
+func resourceRKEKubeconfigWrite() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceRKEKubeconfigWriteCreate,
+		Read:   resourceRKEKubeconfigWriteRead,
+		Update: resourceRKEKubeconfigWriteCreate,
+		Delete: resourceRKEKubeconfigWriteDelete,
+
+		Schema: map[string]*schema.Schema{
+			"kube_config_yaml": {
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"context_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"path": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+func resourceRKEKubeconfigWriteCreate(d *schema.ResourceData, meta interface{}) error {
+	path := d.Get("path").(string)
+	contextName := d.Get("context_name").(string)
+
+	generated, err := loadKubeConfig(d.Get("kube_config_yaml").(string))
+	if err != nil {
+		return fmt.Errorf("parsing kube_config_yaml: %s", err)
+	}
+
+	base, err := loadKubeConfigFile(path)
+	if err != nil {
+		return fmt.Errorf("loading %s: %s", path, err)
+	}
+
+	if err := mergeKubeConfig(base, generated, contextName); err != nil {
+		return err
+	}
+
+	out, err := clientcmd.Write(*base)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, out, 0600); err != nil {
+		return fmt.Errorf("writing %s: %s", path, err)
+	}
+
+	d.SetId(contextName)
+	return nil
+}
+
+func resourceRKEKubeconfigWriteRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+func resourceRKEKubeconfigWriteDelete(d *schema.ResourceData, meta interface{}) error {
+	path := d.Get("path").(string)
+
+	base, err := loadKubeConfigFile(path)
+	if err != nil {
+		return fmt.Errorf("loading %s: %s", path, err)
+	}
+
+	removeKubeConfigContext(base, d.Get("context_name").(string))
+
+	out, err := clientcmd.Write(*base)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, out, 0600); err != nil {
+		return fmt.Errorf("writing %s: %s", path, err)
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,979 @@
+package rke
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rancher/rke/cluster"
+	"github.com/rancher/rke/hosts"
+	v3 "github.com/rancher/types/apis/management.cattle.io/v3"
+)
+
+// rkeResourceGetter is the subset of *schema.ResourceData the parseResource* helpers
+// need. It lets the unit tests drive them with a lightweight fake instead of a real
+// schema.ResourceData.
+type rkeResourceGetter interface {
+	GetOk(key string) (interface{}, bool)
+}
+
+// stateBuilder is the subset of *schema.ResourceData that clusterToState needs in
+// order to populate the resource's state.
+type stateBuilder interface {
+	Set(key string, value interface{}) error
+	SetId(id string)
+}
+
+func mapGetString(m map[string]interface{}, key string) string {
+	if v, ok := m[key]; ok && v != nil {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func mapGetBool(m map[string]interface{}, key string) bool {
+	if v, ok := m[key]; ok && v != nil {
+		if b, ok := v.(bool); ok {
+			return b
+		}
+	}
+	return false
+}
+
+func mapGetInt(m map[string]interface{}, key string) int {
+	if v, ok := m[key]; ok && v != nil {
+		if i, ok := v.(int); ok {
+			return i
+		}
+	}
+	return 0
+}
+
+func toStringSlice(raw []interface{}) []string {
+	out := make([]string, len(raw))
+	for i, r := range raw {
+		out[i] = r.(string)
+	}
+	return out
+}
+
+func mapGetStringSlice(m map[string]interface{}, key string) []string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	raw, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	return toStringSlice(raw)
+}
+
+func toStringMap(raw map[string]interface{}) map[string]string {
+	out := make(map[string]string, len(raw))
+	for k, v := range raw {
+		out[k] = v.(string)
+	}
+	return out
+}
+
+func mapGetStringMap(m map[string]interface{}, key string) map[string]string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	raw, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return toStringMap(raw)
+}
+
+// firstBlock pulls the single nested map out of a MaxItems:1 TypeList block, returning
+// false when the block was never set.
+func firstBlock(d rkeResourceGetter, key string) (map[string]interface{}, bool) {
+	v, ok := d.GetOk(key)
+	if !ok {
+		return nil, false
+	}
+	return mapGetBlock(map[string]interface{}{key: v}, key)
+}
+
+// mapGetBlock is firstBlock for a MaxItems:1 block nested inside an already-decoded
+// map, e.g. network.cilium.etcd.
+func mapGetBlock(m map[string]interface{}, key string) (map[string]interface{}, bool) {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil, false
+	}
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	sub, ok := list[0].(map[string]interface{})
+	return sub, ok
+}
+
+func parsePortOrZero(s string) int {
+	if s == "" {
+		return 0
+	}
+	p, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return p
+}
+
+func privateKeyToPEM(key *rsa.PrivateKey) string {
+	block := &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}
+	return string(pem.EncodeToMemory(block))
+}
+
+func certificateToPEM(cert *x509.Certificate) string {
+	block := &pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: cert.Raw,
+	}
+	return string(pem.EncodeToMemory(block))
+}
+
+func mapGetTaints(m map[string]interface{}, key string) []v3.RKETaint {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	raw, ok := v.([]interface{})
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	taints := make([]v3.RKETaint, len(raw))
+	for i, item := range raw {
+		tm := item.(map[string]interface{})
+		taints[i] = v3.RKETaint{
+			Key:    mapGetString(tm, "key"),
+			Value:  mapGetString(tm, "value"),
+			Effect: mapGetString(tm, "effect"),
+		}
+	}
+	return taints
+}
+
+func mapGetABACPolicies(m map[string]interface{}, key string) []abacPolicy {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	raw, ok := v.([]interface{})
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	policies := make([]abacPolicy, len(raw))
+	for i, item := range raw {
+		pm := item.(map[string]interface{})
+		policies[i] = abacPolicy{
+			User:            mapGetString(pm, "user"),
+			Group:           mapGetString(pm, "group"),
+			Namespace:       mapGetString(pm, "namespace"),
+			Resource:        mapGetString(pm, "resource"),
+			APIGroup:        mapGetString(pm, "api_group"),
+			NonResourcePath: mapGetString(pm, "nonresource_path"),
+			Readonly:        mapGetBool(pm, "readonly"),
+		}
+	}
+	return policies
+}
+
+func taintsToMaps(taints []v3.RKETaint) []interface{} {
+	out := make([]interface{}, len(taints))
+	for i, t := range taints {
+		out[i] = map[string]interface{}{
+			"key":    t.Key,
+			"value":  t.Value,
+			"effect": t.Effect,
+		}
+	}
+	return out
+}
+
+func etcdBackupConfigToMaps(bc *v3.BackupConfig) []interface{} {
+	if bc == nil {
+		return []interface{}{}
+	}
+	return []interface{}{map[string]interface{}{
+		"interval":         bc.Interval,
+		"retention":        bc.Retention,
+		"s3_backup_config": etcdS3BackupConfigToMaps(bc.S3BackupConfig),
+	}}
+}
+
+func etcdS3BackupConfigToMaps(s3 *v3.S3BackupConfig) []interface{} {
+	if s3 == nil {
+		return []interface{}{}
+	}
+	return []interface{}{map[string]interface{}{
+		"endpoint":   s3.Endpoint,
+		"bucket":     s3.Bucket,
+		"region":     s3.Region,
+		"access_key": s3.AccessKey,
+		"secret_key": s3.SecretKey,
+		"folder":     s3.Folder,
+	}}
+}
+
+func parseResourceRKEConfigNode(d rkeResourceGetter) ([]v3.RKEConfigNode, error) {
+	v, ok := d.GetOk("nodes")
+	if !ok {
+		return nil, nil
+	}
+	raw := v.([]interface{})
+	nodes := make([]v3.RKEConfigNode, len(raw))
+	for i, item := range raw {
+		m := item.(map[string]interface{})
+
+		var port string
+		if p, ok := m["port"]; ok {
+			port = strconv.Itoa(p.(int))
+		}
+
+		nodes[i] = v3.RKEConfigNode{
+			NodeName:         mapGetString(m, "node_name"),
+			Address:          mapGetString(m, "address"),
+			Port:             port,
+			InternalAddress:  mapGetString(m, "internal_address"),
+			Role:             mapGetStringSlice(m, "role"),
+			HostnameOverride: mapGetString(m, "hostname_override"),
+			User:             mapGetString(m, "user"),
+			DockerSocket:     mapGetString(m, "docker_socket"),
+			SSHAgentAuth:     mapGetBool(m, "ssh_agent_auth"),
+			SSHKey:           mapGetString(m, "ssh_key"),
+			SSHKeyPath:       mapGetString(m, "ssh_key_path"),
+			SSHCert:          mapGetString(m, "ssh_cert"),
+			SSHCertPath:      mapGetString(m, "ssh_cert_path"),
+			Labels:           mapGetStringMap(m, "labels"),
+			Taints:           mapGetTaints(m, "taints"),
+		}
+	}
+	return nodes, nil
+}
+
+func parseBaseService(m map[string]interface{}) v3.BaseService {
+	return v3.BaseService{
+		Image:      mapGetString(m, "image"),
+		ExtraArgs:  mapGetStringMap(m, "extra_args"),
+		ExtraBinds: mapGetStringSlice(m, "extra_binds"),
+		ExtraEnv:   mapGetStringSlice(m, "extra_env"),
+	}
+}
+
+func parseResourceETCDService(d rkeResourceGetter) (*v3.ETCDService, error) {
+	m, ok := firstBlock(d, "services_etcd")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.ETCDService{
+		BaseService:  parseBaseService(m),
+		ExternalURLs: mapGetStringSlice(m, "external_urls"),
+		CACert:       mapGetString(m, "ca_cert"),
+		Cert:         mapGetString(m, "cert"),
+		Key:          mapGetString(m, "key"),
+		Path:         mapGetString(m, "path"),
+		Snapshot:     mapGetBool(m, "snapshot"),
+		Retention:    mapGetString(m, "retention"),
+		Creation:     mapGetString(m, "creation"),
+		BackupConfig: parseETCDBackupConfig(m),
+	}, nil
+}
+
+func parseETCDBackupConfig(m map[string]interface{}) *v3.BackupConfig {
+	bc, ok := mapGetBlock(m, "backup_config")
+	if !ok {
+		return nil
+	}
+	return &v3.BackupConfig{
+		Interval:       mapGetInt(bc, "interval"),
+		Retention:      mapGetInt(bc, "retention"),
+		S3BackupConfig: parseETCDS3BackupConfig(bc),
+	}
+}
+
+func parseETCDS3BackupConfig(m map[string]interface{}) *v3.S3BackupConfig {
+	s3, ok := mapGetBlock(m, "s3_backup_config")
+	if !ok {
+		return nil
+	}
+	return &v3.S3BackupConfig{
+		Endpoint:  mapGetString(s3, "endpoint"),
+		Bucket:    mapGetString(s3, "bucket"),
+		Region:    mapGetString(s3, "region"),
+		AccessKey: mapGetString(s3, "access_key"),
+		SecretKey: mapGetString(s3, "secret_key"),
+		Folder:    mapGetString(s3, "folder"),
+	}
+}
+
+func parseResourceKubeAPIService(d rkeResourceGetter) (*v3.KubeAPIService, error) {
+	m, ok := firstBlock(d, "services_kube_api")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.KubeAPIService{
+		BaseService:           parseBaseService(m),
+		ServiceClusterIPRange: mapGetString(m, "service_cluster_ip_range"),
+		ServiceNodePortRange:  mapGetString(m, "service_node_port_range"),
+		PodSecurityPolicy:     mapGetBool(m, "pod_security_policy"),
+		AlwaysPullImages:      mapGetBool(m, "always_pull_images"),
+	}, nil
+}
+
+func parseResourceKubeControllerService(d rkeResourceGetter) (*v3.KubeControllerService, error) {
+	m, ok := firstBlock(d, "services_kube_controller")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.KubeControllerService{
+		BaseService:           parseBaseService(m),
+		ClusterCIDR:           mapGetString(m, "cluster_cidr"),
+		ServiceClusterIPRange: mapGetString(m, "service_cluster_ip_range"),
+	}, nil
+}
+
+func parseResourceSchedulerService(d rkeResourceGetter) (*v3.SchedulerService, error) {
+	m, ok := firstBlock(d, "services_scheduler")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.SchedulerService{
+		BaseService: parseBaseService(m),
+	}, nil
+}
+
+func parseResourceKubeletService(d rkeResourceGetter) (*v3.KubeletService, error) {
+	m, ok := firstBlock(d, "services_kubelet")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.KubeletService{
+		BaseService:         parseBaseService(m),
+		ClusterDomain:       mapGetString(m, "cluster_domain"),
+		InfraContainerImage: mapGetString(m, "infra_container_image"),
+		ClusterDNSServer:    mapGetString(m, "cluster_dns_server"),
+		FailSwapOn:          mapGetBool(m, "fail_swap_on"),
+	}, nil
+}
+
+func parseResourceKubeproxyService(d rkeResourceGetter) (*v3.KubeproxyService, error) {
+	m, ok := firstBlock(d, "services_kubeproxy")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.KubeproxyService{
+		BaseService: parseBaseService(m),
+	}, nil
+}
+
+// networkAddonState carries the network.cilium/network.multus sub-fields that
+// clusterToState can't recover from cluster.Cluster alone - attachment_definition
+// isn't part of v3.NetworkConfig at all, and cilium's etcd node_selector is only ever
+// rendered into the addon manifest, never stored in Network.Options - so they have to
+// be threaded through from the resource data the same way
+// parseResourceVersionServiceOptionsOverride's result is.
+type networkAddonState struct {
+	CiliumEtcdNodeSelector      map[string]string
+	MultusAttachmentDefinitions []interface{}
+}
+
+// parseResourceNetworkAddonState reads the parts of network.cilium/network.multus that
+// clusterToState needs in order to round-trip those blocks into state.
+func parseResourceNetworkAddonState(d rkeResourceGetter) networkAddonState {
+	var state networkAddonState
+
+	network, ok := firstBlock(d, "network")
+	if !ok {
+		return state
+	}
+
+	if cilium, ok := mapGetBlock(network, "cilium"); ok {
+		if etcd, ok := mapGetBlock(cilium, "etcd"); ok {
+			state.CiliumEtcdNodeSelector = mapGetStringMap(etcd, "node_selector")
+		}
+	}
+
+	if multus, ok := mapGetBlock(network, "multus"); ok {
+		if raw, ok := multus["attachment_definition"].([]interface{}); ok {
+			state.MultusAttachmentDefinitions = raw
+		}
+	}
+
+	return state
+}
+
+func parseResourceNetwork(d rkeResourceGetter) (*v3.NetworkConfig, error) {
+	m, ok := firstBlock(d, "network")
+	if !ok {
+		return nil, nil
+	}
+
+	options := mapGetStringMap(m, "options")
+
+	if cilium, ok := mapGetBlock(m, "cilium"); ok {
+		if options == nil {
+			options = map[string]string{}
+		}
+		for k, v := range ciliumOptions(cilium) {
+			options[k] = v
+		}
+		return &v3.NetworkConfig{
+			Plugin:  "cilium",
+			Options: options,
+		}, nil
+	}
+
+	if multus, ok := mapGetBlock(m, "multus"); ok {
+		if options == nil {
+			options = map[string]string{}
+		}
+		for k, v := range multusOptions(multus) {
+			options[k] = v
+		}
+		return &v3.NetworkConfig{
+			Plugin:  "multus",
+			Options: options,
+		}, nil
+	}
+
+	return &v3.NetworkConfig{
+		Plugin:  mapGetString(m, "plugin"),
+		Options: options,
+	}, nil
+}
+
+func parseResourceAuthentication(d rkeResourceGetter) (*v3.AuthnConfig, error) {
+	m, ok := firstBlock(d, "authentication")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.AuthnConfig{
+		Strategy: mapGetString(m, "strategy"),
+		Options:  mapGetStringMap(m, "options"),
+		SANs:     mapGetStringSlice(m, "sans"),
+	}, nil
+}
+
+func parseResourceAddons(d rkeResourceGetter) (string, error) {
+	v, ok := d.GetOk("addons")
+	if !ok {
+		return "", nil
+	}
+	return v.(string), nil
+}
+
+func parseResourceAddonsInclude(d rkeResourceGetter) ([]string, error) {
+	v, ok := d.GetOk("addons_include")
+	if !ok {
+		return nil, nil
+	}
+	return toStringSlice(v.([]interface{})), nil
+}
+
+func parseResourceSystemImages(d rkeResourceGetter) (*v3.RKESystemImages, error) {
+	m, ok := firstBlock(d, "system_images")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.RKESystemImages{
+		Etcd:                      mapGetString(m, "etcd"),
+		Alpine:                    mapGetString(m, "alpine"),
+		NginxProxy:                mapGetString(m, "nginx_proxy"),
+		CertDownloader:            mapGetString(m, "cert_downloader"),
+		KubernetesServicesSidecar: mapGetString(m, "kubernetes_services_sidecar"),
+		KubeDNS:                   mapGetString(m, "kube_dns"),
+		DNSmasq:                   mapGetString(m, "dnsmasq"),
+		KubeDNSSidecar:            mapGetString(m, "kube_dns_sidecar"),
+		KubeDNSAutoscaler:         mapGetString(m, "kube_dns_autoscaler"),
+		Kubernetes:                mapGetString(m, "kubernetes"),
+		Flannel:                   mapGetString(m, "flannel"),
+		FlannelCNI:                mapGetString(m, "flannel_cni"),
+		CalicoNode:                mapGetString(m, "calico_node"),
+		CalicoCNI:                 mapGetString(m, "calico_cni"),
+		CalicoControllers:         mapGetString(m, "calico_controllers"),
+		CalicoCtl:                 mapGetString(m, "calico_ctl"),
+		CanalNode:                 mapGetString(m, "canal_node"),
+		CanalCNI:                  mapGetString(m, "canal_cni"),
+		CanalFlannel:              mapGetString(m, "canal_flannel"),
+		WeaveNode:                 mapGetString(m, "weave_node"),
+		WeaveCNI:                  mapGetString(m, "weave_cni"),
+		PodInfraContainer:         mapGetString(m, "pod_infra_container"),
+		Ingress:                   mapGetString(m, "ingress"),
+		IngressBackend:            mapGetString(m, "ingress_backend"),
+		Dashboard:                 mapGetString(m, "dashboard"),
+		Heapster:                  mapGetString(m, "heapster"),
+		Grafana:                   mapGetString(m, "grafana"),
+		Influxdb:                  mapGetString(m, "influxdb"),
+		Tiller:                    mapGetString(m, "tiller"),
+	}, nil
+}
+
+func parseResourceSSHKeyPath(d rkeResourceGetter) (string, error) {
+	v, ok := d.GetOk("ssh_key_path")
+	if !ok {
+		return "", nil
+	}
+	return v.(string), nil
+}
+
+func parseResourceSSHAgentAuth(d rkeResourceGetter) (bool, error) {
+	v, ok := d.GetOk("ssh_agent_auth")
+	if !ok {
+		return false, nil
+	}
+	return v.(bool), nil
+}
+
+// parseResourceAuthorization also returns any ABAC policies set on the block, since
+// those aren't part of v3.AuthzConfig - they're rendered into a policy file and
+// distributed to controlplane nodes by the caller. Setting policies when mode doesn't
+// include "abac" (as "abac" or a combined mode like "rbac,abac") is rejected here,
+// since the SDK v1 schema has no cross-field ValidateFunc.
+func parseResourceAuthorization(d rkeResourceGetter) (*v3.AuthzConfig, []abacPolicy, error) {
+	m, ok := firstBlock(d, "authorization")
+	if !ok {
+		return nil, nil, nil
+	}
+
+	mode := mapGetString(m, "mode")
+	policies := mapGetABACPolicies(m, "policies")
+	if len(policies) > 0 && !authzModeHasABAC(mode) {
+		return nil, nil, fmt.Errorf("authorization.policies is only valid when authorization.mode includes \"abac\" (e.g. \"abac\" or \"rbac,abac\"), got %q", mode)
+	}
+
+	return &v3.AuthzConfig{
+		Mode:    mode,
+		Options: mapGetStringMap(m, "options"),
+	}, policies, nil
+}
+
+func parseResourceIgnoreDockerVersion(d rkeResourceGetter) (bool, error) {
+	v, ok := d.GetOk("ignore_docker_version")
+	if !ok {
+		return false, nil
+	}
+	return v.(bool), nil
+}
+
+func parseResourceVersion(d rkeResourceGetter) (string, error) {
+	v, ok := d.GetOk("kubernetes_version")
+	if !ok {
+		return "", nil
+	}
+	return v.(string), nil
+}
+
+func parseResourcePrivateRegistries(d rkeResourceGetter) ([]v3.PrivateRegistry, error) {
+	v, ok := d.GetOk("private_registries")
+	if !ok {
+		return nil, nil
+	}
+	raw := v.([]interface{})
+	registries := make([]v3.PrivateRegistry, len(raw))
+	for i, item := range raw {
+		m := item.(map[string]interface{})
+		registries[i] = v3.PrivateRegistry{
+			URL:      mapGetString(m, "url"),
+			User:     mapGetString(m, "user"),
+			Password: mapGetString(m, "password"),
+		}
+	}
+	return registries, nil
+}
+
+func parseResourceIngress(d rkeResourceGetter) (*v3.IngressConfig, error) {
+	m, ok := firstBlock(d, "ingress")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.IngressConfig{
+		Provider:     mapGetString(m, "provider"),
+		Options:      mapGetStringMap(m, "options"),
+		NodeSelector: mapGetStringMap(m, "node_selector"),
+	}, nil
+}
+
+func parseResourceClusterName(d rkeResourceGetter) (string, error) {
+	v, ok := d.GetOk("cluster_name")
+	if !ok {
+		return "", nil
+	}
+	return v.(string), nil
+}
+
+func parseResourceCloudProvider(d rkeResourceGetter) (*v3.CloudProvider, error) {
+	m, ok := firstBlock(d, "cloud_provider")
+	if !ok {
+		return nil, nil
+	}
+	return &v3.CloudProvider{
+		Name:        mapGetString(m, "name"),
+		CloudConfig: mapGetStringMap(m, "cloud_config"),
+	}, nil
+}
+
+// parseResourceWaitForReady reads the wait_for_ready block into a waitForReadyConfig,
+// falling back to the package defaults for any duration the caller left unset. Unlike
+// the other parseResource* helpers this isn't part of RancherKubernetesEngineConfig -
+// it only drives the post-ClusterUp readiness poll.
+func parseResourceWaitForReady(d rkeResourceGetter) (*waitForReadyConfig, error) {
+	m, ok := firstBlock(d, "wait_for_ready")
+	if !ok {
+		return nil, nil
+	}
+
+	cfg := &waitForReadyConfig{
+		Enabled:       mapGetBool(m, "enabled"),
+		Timeout:       defaultWaitForReadyTimeout,
+		PollInterval:  defaultWaitForReadyPollInterval,
+		MinReadyNodes: mapGetInt(m, "min_ready_nodes"),
+	}
+
+	if v := mapGetString(m, "timeout"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("wait_for_ready.timeout: %s", err)
+		}
+		cfg.Timeout = timeout
+	}
+
+	if v := mapGetString(m, "poll_interval"); v != "" {
+		pollInterval, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("wait_for_ready.poll_interval: %s", err)
+		}
+		cfg.PollInterval = pollInterval
+	}
+
+	return cfg, nil
+}
+
+func hostsToMaps(hs []*hosts.Host) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(hs))
+	for i, h := range hs {
+		out[i] = map[string]interface{}{
+			"node_name": h.NodeName,
+			"address":   h.Address,
+		}
+	}
+	return out
+}
+
+// clusterToState flattens the cluster.Cluster produced by a ClusterUp run back into
+// the resource's Terraform state, mirroring the shape parseResource* built it from.
+func clusterToState(c *cluster.Cluster, d stateBuilder, versionServiceOptionsOverride map[string]serviceOptionsSet, networkAddons networkAddonState, abacPolicies []abacPolicy) error {
+	nodes := make([]interface{}, len(c.Nodes))
+	for i, n := range c.Nodes {
+		nm := map[string]interface{}{
+			"node_name":         n.NodeName,
+			"address":           n.Address,
+			"port":              parsePortOrZero(n.Port),
+			"internal_address":  n.InternalAddress,
+			"role":              n.Role,
+			"hostname_override": n.HostnameOverride,
+			"user":              n.User,
+			"docker_socket":     n.DockerSocket,
+			"ssh_agent_auth":    n.SSHAgentAuth,
+			"ssh_key":           n.SSHKey,
+			"ssh_key_path":      n.SSHKeyPath,
+			"ssh_cert":          n.SSHCert,
+			"ssh_cert_path":     n.SSHCertPath,
+			"labels":            n.Labels,
+		}
+		if len(n.Taints) > 0 {
+			nm["taints"] = taintsToMaps(n.Taints)
+		}
+		nodes[i] = nm
+	}
+	if err := d.Set("nodes", nodes); err != nil {
+		return err
+	}
+
+	etcd := c.Services.Etcd
+	if err := d.Set("services_etcd", []interface{}{map[string]interface{}{
+		"image":         etcd.Image,
+		"extra_args":    etcd.ExtraArgs,
+		"extra_binds":   etcd.ExtraBinds,
+		"extra_env":     etcd.ExtraEnv,
+		"external_urls": etcd.ExternalURLs,
+		"ca_cert":       etcd.CACert,
+		"cert":          etcd.Cert,
+		"key":           etcd.Key,
+		"path":          etcd.Path,
+		"snapshot":      etcd.Snapshot,
+		"retention":     etcd.Retention,
+		"creation":      etcd.Creation,
+		"backup_config": etcdBackupConfigToMaps(etcd.BackupConfig),
+		"resolved_extra_args": resolveServiceExtraArgs(c.Version, versionServiceOptionsOverride, func(s serviceOptionsSet) map[string]string {
+			return s.Etcd
+		}, etcd.ExtraArgs),
+	}}); err != nil {
+		return err
+	}
+
+	kubeAPI := c.Services.KubeAPI
+	if err := d.Set("services_kube_api", []interface{}{map[string]interface{}{
+		"image":                    kubeAPI.Image,
+		"extra_args":               kubeAPI.ExtraArgs,
+		"extra_binds":              kubeAPI.ExtraBinds,
+		"extra_env":                kubeAPI.ExtraEnv,
+		"service_cluster_ip_range": kubeAPI.ServiceClusterIPRange,
+		"service_node_port_range":  kubeAPI.ServiceNodePortRange,
+		"pod_security_policy":      kubeAPI.PodSecurityPolicy,
+		"always_pull_images":       kubeAPI.AlwaysPullImages,
+		"resolved_extra_args": resolveServiceExtraArgs(c.Version, versionServiceOptionsOverride, func(s serviceOptionsSet) map[string]string {
+			return s.KubeAPI
+		}, kubeAPI.ExtraArgs),
+	}}); err != nil {
+		return err
+	}
+
+	kubeController := c.Services.KubeController
+	if err := d.Set("services_kube_controller", []interface{}{map[string]interface{}{
+		"image":                    kubeController.Image,
+		"extra_args":               kubeController.ExtraArgs,
+		"extra_binds":              kubeController.ExtraBinds,
+		"extra_env":                kubeController.ExtraEnv,
+		"cluster_cidr":             kubeController.ClusterCIDR,
+		"service_cluster_ip_range": kubeController.ServiceClusterIPRange,
+		"resolved_extra_args": resolveServiceExtraArgs(c.Version, versionServiceOptionsOverride, func(s serviceOptionsSet) map[string]string {
+			return s.KubeController
+		}, kubeController.ExtraArgs),
+	}}); err != nil {
+		return err
+	}
+
+	scheduler := c.Services.Scheduler
+	if err := d.Set("services_scheduler", []interface{}{map[string]interface{}{
+		"image":       scheduler.Image,
+		"extra_args":  scheduler.ExtraArgs,
+		"extra_binds": scheduler.ExtraBinds,
+		"extra_env":   scheduler.ExtraEnv,
+		"resolved_extra_args": resolveServiceExtraArgs(c.Version, versionServiceOptionsOverride, func(s serviceOptionsSet) map[string]string {
+			return s.Scheduler
+		}, scheduler.ExtraArgs),
+	}}); err != nil {
+		return err
+	}
+
+	kubelet := c.Services.Kubelet
+	if err := d.Set("services_kubelet", []interface{}{map[string]interface{}{
+		"image":                 kubelet.Image,
+		"extra_args":            kubelet.ExtraArgs,
+		"extra_binds":           kubelet.ExtraBinds,
+		"extra_env":             kubelet.ExtraEnv,
+		"cluster_domain":        kubelet.ClusterDomain,
+		"infra_container_image": kubelet.InfraContainerImage,
+		"cluster_dns_server":    kubelet.ClusterDNSServer,
+		"fail_swap_on":          kubelet.FailSwapOn,
+		"resolved_extra_args": resolveServiceExtraArgs(c.Version, versionServiceOptionsOverride, func(s serviceOptionsSet) map[string]string {
+			return s.Kubelet
+		}, kubelet.ExtraArgs),
+	}}); err != nil {
+		return err
+	}
+
+	kubeproxy := c.Services.Kubeproxy
+	if err := d.Set("services_kubeproxy", []interface{}{map[string]interface{}{
+		"image":       kubeproxy.Image,
+		"extra_args":  kubeproxy.ExtraArgs,
+		"extra_binds": kubeproxy.ExtraBinds,
+		"extra_env":   kubeproxy.ExtraEnv,
+		"resolved_extra_args": resolveServiceExtraArgs(c.Version, versionServiceOptionsOverride, func(s serviceOptionsSet) map[string]string {
+			return s.Kubeproxy
+		}, kubeproxy.ExtraArgs),
+	}}); err != nil {
+		return err
+	}
+
+	networkState := map[string]interface{}{
+		"plugin":  c.Network.Plugin,
+		"options": c.Network.Options,
+	}
+	switch c.Network.Plugin {
+	case "cilium":
+		networkState["cilium"] = []interface{}{ciliumOptionsToMap(c.Network.Options, networkAddons.CiliumEtcdNodeSelector)}
+	case "multus":
+		networkState["multus"] = []interface{}{multusOptionsToMap(c.Network.Options, networkAddons.MultusAttachmentDefinitions)}
+	}
+	if err := d.Set("network", []interface{}{networkState}); err != nil {
+		return err
+	}
+
+	if err := d.Set("authentication", []interface{}{map[string]interface{}{
+		"strategy": c.Authentication.Strategy,
+		"options":  c.Authentication.Options,
+		"sans":     c.Authentication.SANs,
+	}}); err != nil {
+		return err
+	}
+
+	if err := d.Set("addons", c.Addons); err != nil {
+		return err
+	}
+	if err := d.Set("addons_include", c.AddonsInclude); err != nil {
+		return err
+	}
+
+	si := c.SystemImages
+	if err := d.Set("system_images", []interface{}{map[string]interface{}{
+		"etcd":                        si.Etcd,
+		"alpine":                      si.Alpine,
+		"nginx_proxy":                 si.NginxProxy,
+		"cert_downloader":             si.CertDownloader,
+		"kubernetes_services_sidecar": si.KubernetesServicesSidecar,
+		"kube_dns":                    si.KubeDNS,
+		"dnsmasq":                     si.DNSmasq,
+		"kube_dns_sidecar":            si.KubeDNSSidecar,
+		"kube_dns_autoscaler":         si.KubeDNSAutoscaler,
+		"kubernetes":                  si.Kubernetes,
+		"flannel":                     si.Flannel,
+		"flannel_cni":                 si.FlannelCNI,
+		"calico_node":                 si.CalicoNode,
+		"calico_cni":                  si.CalicoCNI,
+		"calico_controllers":          si.CalicoControllers,
+		"calico_ctl":                  si.CalicoCtl,
+		"canal_node":                  si.CanalNode,
+		"canal_cni":                   si.CanalCNI,
+		"canal_flannel":               si.CanalFlannel,
+		"weave_node":                  si.WeaveNode,
+		"weave_cni":                   si.WeaveCNI,
+		"pod_infra_container":         si.PodInfraContainer,
+		"ingress":                     si.Ingress,
+		"ingress_backend":             si.IngressBackend,
+		"dashboard":                   si.Dashboard,
+		"heapster":                    si.Heapster,
+		"grafana":                     si.Grafana,
+		"influxdb":                    si.Influxdb,
+		"tiller":                      si.Tiller,
+	}}); err != nil {
+		return err
+	}
+
+	if err := d.Set("ssh_key_path", c.SSHKeyPath); err != nil {
+		return err
+	}
+	if err := d.Set("ssh_agent_auth", c.SSHAgentAuth); err != nil {
+		return err
+	}
+
+	authorizationState := map[string]interface{}{
+		"mode":    c.Authorization.Mode,
+		"options": c.Authorization.Options,
+	}
+	if len(abacPolicies) > 0 {
+		authorizationState["policies"] = abacPoliciesToMaps(abacPolicies)
+	}
+	if err := d.Set("authorization", []interface{}{authorizationState}); err != nil {
+		return err
+	}
+
+	if err := d.Set("ignore_docker_version", c.IgnoreDockerVersion); err != nil {
+		return err
+	}
+	if err := d.Set("kubernetes_version", c.Version); err != nil {
+		return err
+	}
+
+	registries := make([]interface{}, len(c.PrivateRegistries))
+	for i, r := range c.PrivateRegistries {
+		registries[i] = map[string]interface{}{
+			"url":      r.URL,
+			"user":     r.User,
+			"password": r.Password,
+		}
+	}
+	if err := d.Set("private_registries", registries); err != nil {
+		return err
+	}
+
+	if err := d.Set("ingress", []interface{}{map[string]interface{}{
+		"provider":      c.Ingress.Provider,
+		"options":       c.Ingress.Options,
+		"node_selector": c.Ingress.NodeSelector,
+	}}); err != nil {
+		return err
+	}
+
+	if err := d.Set("cluster_name", c.ClusterName); err != nil {
+		return err
+	}
+
+	if err := d.Set("cloud_provider", []interface{}{map[string]interface{}{
+		"name":         c.CloudProvider.Name,
+		"cloud_config": c.CloudProvider.CloudConfig,
+	}}); err != nil {
+		return err
+	}
+
+	certs := make([]interface{}, 0, len(c.Certificates))
+	for id, entry := range c.Certificates {
+		certs = append(certs, map[string]interface{}{
+			"id":              id,
+			"certificate":     certificateToPEM(entry.Certificate),
+			"key":             privateKeyToPEM(entry.Key),
+			"config":          entry.Config,
+			"name":            entry.Name,
+			"common_name":     entry.CommonName,
+			"ou_name":         entry.OUName,
+			"env_name":        entry.EnvName,
+			"path":            entry.Path,
+			"key_env_name":    entry.KeyEnvName,
+			"key_path":        entry.KeyPath,
+			"config_env_name": entry.ConfigEnvName,
+			"config_path":     entry.ConfigPath,
+		})
+	}
+	if err := d.Set("certificates", certs); err != nil {
+		return err
+	}
+
+	if err := d.Set("cluster_domain", c.ClusterDomain); err != nil {
+		return err
+	}
+	if err := d.Set("cluster_cidr", c.ClusterCIDR); err != nil {
+		return err
+	}
+	if err := d.Set("cluster_dns_server", c.ClusterDNSServer); err != nil {
+		return err
+	}
+
+	if err := d.Set("etcd_hosts", hostsToMaps(c.EtcdHosts)); err != nil {
+		return err
+	}
+	if err := d.Set("worker_hosts", hostsToMaps(c.WorkerHosts)); err != nil {
+		return err
+	}
+	if err := d.Set("control_plane_hosts", hostsToMaps(c.ControlPlaneHosts)); err != nil {
+		return err
+	}
+	if err := d.Set("inactive_hosts", hostsToMaps(c.InactiveHosts)); err != nil {
+		return err
+	}
+
+	kubeConfigState, err := adminKubeConfigState(c)
+	if err != nil {
+		return err
+	}
+	for k, v := range kubeConfigState {
+		if err := d.Set(k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -6,12 +6,14 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"testing"
+	"time"
 
 	"github.com/rancher/rke/cluster"
 	"github.com/rancher/rke/hosts"
 	"github.com/rancher/rke/pki"
 	"github.com/rancher/types/apis/management.cattle.io/v3"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 var (
@@ -124,6 +126,8 @@ func TestParseResourceRKEConfigNode(t *testing.T) {
 						"ssh_agent_auth":    true,
 						"ssh_key":           "ssh_key",
 						"ssh_key_path":      "ssh_key_path",
+						"ssh_cert":          "ssh_cert",
+						"ssh_cert_path":     "ssh_cert_path",
 						"labels": map[string]interface{}{
 							"foo": "foo",
 							"bar": "bar",
@@ -144,6 +148,8 @@ func TestParseResourceRKEConfigNode(t *testing.T) {
 					SSHAgentAuth:     true,
 					SSHKey:           "ssh_key",
 					SSHKeyPath:       "ssh_key_path",
+					SSHCert:          "ssh_cert",
+					SSHCertPath:      "ssh_cert_path",
 					Labels: map[string]string{
 						"foo": "foo",
 						"bar": "bar",
@@ -151,6 +157,47 @@ func TestParseResourceRKEConfigNode(t *testing.T) {
 				},
 			},
 		},
+		{
+			caseName: "with taints",
+			resourceData: map[string]interface{}{
+				"nodes": []interface{}{
+					map[string]interface{}{
+						"address": "192.2.0.1",
+						"role":    []interface{}{"worker"},
+						"taints": []interface{}{
+							map[string]interface{}{
+								"key":    "dedicated",
+								"value":  "gpu",
+								"effect": "NoSchedule",
+							},
+							map[string]interface{}{
+								"key":    "special",
+								"value":  "true",
+								"effect": "PreferNoSchedule",
+							},
+						},
+					},
+				},
+			},
+			expectNodes: []v3.RKEConfigNode{
+				{
+					Address: "192.2.0.1",
+					Role:    []string{"worker"},
+					Taints: []v3.RKETaint{
+						{
+							Key:    "dedicated",
+							Value:  "gpu",
+							Effect: "NoSchedule",
+						},
+						{
+							Key:    "special",
+							Value:  "true",
+							Effect: "PreferNoSchedule",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -180,6 +227,7 @@ func TestParseResourceETCDService(t *testing.T) {
 							"bar": "bar",
 						},
 						"extra_binds":   []interface{}{"/etc1", "/etc2"},
+						"extra_env":     []interface{}{"FOO=bar"},
 						"external_urls": []interface{}{"https://etcd1.example.com", "https://etcd2.example.com"},
 						"ca_cert":       "ca_cert",
 						"cert":          "cert",
@@ -196,6 +244,7 @@ func TestParseResourceETCDService(t *testing.T) {
 						"bar": "bar",
 					},
 					ExtraBinds: []string{"/etc1", "/etc2"},
+					ExtraEnv:   []string{"FOO=bar"},
 				},
 				ExternalURLs: []string{"https://etcd1.example.com", "https://etcd2.example.com"},
 				CACert:       "ca_cert",
@@ -204,6 +253,51 @@ func TestParseResourceETCDService(t *testing.T) {
 				Path:         "path",
 			},
 		},
+		{
+			caseName: "with snapshot and s3 backup_config",
+			resourceData: map[string]interface{}{
+				"services_etcd": []interface{}{
+					map[string]interface{}{
+						"snapshot":  true,
+						"retention": "72h",
+						"creation":  "12h",
+						"backup_config": []interface{}{
+							map[string]interface{}{
+								"interval":  15,
+								"retention": 10,
+								"s3_backup_config": []interface{}{
+									map[string]interface{}{
+										"endpoint":   "s3.amazonaws.com",
+										"bucket":     "rke-backups",
+										"region":     "us-east-1",
+										"access_key": "access_key",
+										"secret_key": "secret_key",
+										"folder":     "cluster1",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectService: &v3.ETCDService{
+				Snapshot:  true,
+				Retention: "72h",
+				Creation:  "12h",
+				BackupConfig: &v3.BackupConfig{
+					Interval:  15,
+					Retention: 10,
+					S3BackupConfig: &v3.S3BackupConfig{
+						Endpoint:  "s3.amazonaws.com",
+						Bucket:    "rke-backups",
+						Region:    "us-east-1",
+						AccessKey: "access_key",
+						SecretKey: "secret_key",
+						Folder:    "cluster1",
+					},
+				},
+			},
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -234,7 +328,9 @@ func TestParseResourceKubeAPIService(t *testing.T) {
 						},
 						"extra_binds":              []interface{}{"/etc1", "/etc2"},
 						"service_cluster_ip_range": "10.240.0.0/16",
+						"service_node_port_range":  "30000-32767",
 						"pod_security_policy":      true,
+						"always_pull_images":       true,
 					},
 				},
 			},
@@ -248,7 +344,9 @@ func TestParseResourceKubeAPIService(t *testing.T) {
 					ExtraBinds: []string{"/etc1", "/etc2"},
 				},
 				ServiceClusterIPRange: "10.240.0.0/16",
+				ServiceNodePortRange:  "30000-32767",
 				PodSecurityPolicy:     true,
+				AlwaysPullImages:      true,
 			},
 		},
 	}
@@ -474,6 +572,61 @@ func TestParseResourceNetwork(t *testing.T) {
 				},
 			},
 		},
+		{
+			caseName: "cilium with etcd",
+			resourceData: map[string]interface{}{
+				"network": []interface{}{
+					map[string]interface{}{
+						"cilium": []interface{}{
+							map[string]interface{}{
+								"version":               "1.4.2",
+								"ipam":                  "kubernetes",
+								"tunnel":                "vxlan",
+								"enable_bpf_masquerade": true,
+								"enable_hubble":         false,
+								"etcd": []interface{}{
+									map[string]interface{}{
+										"node_selector": map[string]interface{}{
+											"cilium-etcd": "true",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectNetwork: &v3.NetworkConfig{
+				Plugin: "cilium",
+				Options: map[string]string{
+					"cilium-version":        "1.4.2",
+					"ipam":                  "kubernetes",
+					"tunnel":                "vxlan",
+					"enable-bpf-masquerade": "true",
+					"enable-hubble":         "false",
+				},
+			},
+		},
+		{
+			caseName: "multus",
+			resourceData: map[string]interface{}{
+				"network": []interface{}{
+					map[string]interface{}{
+						"multus": []interface{}{
+							map[string]interface{}{
+								"version": "3.4.1",
+							},
+						},
+					},
+				},
+			},
+			expectNetwork: &v3.NetworkConfig{
+				Plugin: "multus",
+				Options: map[string]string{
+					"multus-version": "3.4.1",
+				},
+			},
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -486,6 +639,101 @@ func TestParseResourceNetwork(t *testing.T) {
 	}
 }
 
+func TestLabelCiliumEtcdNodes(t *testing.T) {
+	nodes := []v3.RKEConfigNode{
+		{Address: "10.0.0.1", Role: []string{"etcd"}},
+		{Address: "10.0.0.2", Role: []string{"etcd"}, Labels: map[string]string{"existing": "label"}},
+		{Address: "10.0.0.3", Role: []string{"worker"}},
+	}
+
+	labelCiliumEtcdNodes(nodes, map[string]string{"cilium-etcd": "true"})
+
+	assert.Equal(t, map[string]string{"cilium-etcd": "true"}, nodes[0].Labels)
+	assert.Equal(t, map[string]string{"existing": "label", "cilium-etcd": "true"}, nodes[1].Labels)
+	assert.Nil(t, nodes[2].Labels)
+
+	nodes = []v3.RKEConfigNode{{Address: "10.0.0.1", Role: []string{"etcd"}}}
+	labelCiliumEtcdNodes(nodes, nil)
+	assert.Nil(t, nodes[0].Labels)
+}
+
+func TestParseResourceCiliumEtcdAddon(t *testing.T) {
+	resourceData := map[string]interface{}{
+		"network": []interface{}{
+			map[string]interface{}{
+				"cilium": []interface{}{
+					map[string]interface{}{
+						"etcd": []interface{}{
+							map[string]interface{}{
+								"node_selector": map[string]interface{}{
+									"cilium-etcd": "true",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	d := &dummyResourceData{values: resourceData}
+	addon, ok, err := parseResourceCiliumEtcdAddon(d)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, addon, "kind: EtcdCluster")
+	assert.Contains(t, addon, "cilium-etcd: \"true\"")
+
+	noCilium := &dummyResourceData{values: map[string]interface{}{}}
+	_, ok, err = parseResourceCiliumEtcdAddon(noCilium)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestParseResourceMultusAddon(t *testing.T) {
+	resourceData := map[string]interface{}{
+		"network": []interface{}{
+			map[string]interface{}{
+				"multus": []interface{}{
+					map[string]interface{}{
+						"attachment_definition": []interface{}{
+							map[string]interface{}{
+								"name":      "macvlan-conf",
+								"namespace": "default",
+								"cni_type":  "macvlan",
+								"master":    "eth1",
+								"mode":      "bridge",
+								"ipam": []interface{}{
+									map[string]interface{}{
+										"type":  "whereabouts",
+										"range": "192.168.2.0/24",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	d := &dummyResourceData{values: resourceData}
+	addon, ok, err := parseResourceMultusAddon(d)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, addon, "kind: NetworkAttachmentDefinition")
+	assert.Contains(t, addon, "name: macvlan-conf")
+	assert.Contains(t, addon, "namespace: default")
+	assert.Contains(t, addon, `"type":"macvlan"`)
+	assert.Contains(t, addon, `"master":"eth1"`)
+	assert.Contains(t, addon, `"mode":"bridge"`)
+	assert.Contains(t, addon, `"ipam":{"type":"whereabouts","range":"192.168.2.0/24"}`)
+
+	noMultus := &dummyResourceData{values: map[string]interface{}{}}
+	_, ok, err = parseResourceMultusAddon(noMultus)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
 func TestParseResourceAuthentication(t *testing.T) {
 	testcases := []struct {
 		caseName     string
@@ -661,9 +909,11 @@ func TestParseResourceSSHAgentAuth(t *testing.T) {
 
 func TestParseResourceAuthorization(t *testing.T) {
 	testcases := []struct {
-		caseName     string
-		resourceData map[string]interface{}
-		expectConfig *v3.AuthzConfig
+		caseName       string
+		resourceData   map[string]interface{}
+		expectConfig   *v3.AuthzConfig
+		expectPolicies []abacPolicy
+		expectErr      bool
 	}{
 		{
 			caseName: "all fields",
@@ -686,18 +936,122 @@ func TestParseResourceAuthorization(t *testing.T) {
 				},
 			},
 		},
+		{
+			caseName: "abac with policies",
+			resourceData: map[string]interface{}{
+				"authorization": []interface{}{
+					map[string]interface{}{
+						"mode": "abac",
+						"policies": []interface{}{
+							map[string]interface{}{
+								"user":             "alice",
+								"namespace":        "default",
+								"resource":         "pods",
+								"nonresource_path": "*",
+								"readonly":         true,
+							},
+						},
+					},
+				},
+			},
+			expectConfig: &v3.AuthzConfig{
+				Mode: "abac",
+			},
+			expectPolicies: []abacPolicy{
+				{
+					User:            "alice",
+					Namespace:       "default",
+					Resource:        "pods",
+					NonResourcePath: "*",
+					Readonly:        true,
+				},
+			},
+		},
+		{
+			caseName: "combined rbac,abac mode with policies",
+			resourceData: map[string]interface{}{
+				"authorization": []interface{}{
+					map[string]interface{}{
+						"mode": "rbac,abac",
+						"policies": []interface{}{
+							map[string]interface{}{
+								"group":    "system:masters",
+								"resource": "*",
+							},
+						},
+					},
+				},
+			},
+			expectConfig: &v3.AuthzConfig{
+				Mode: "rbac,abac",
+			},
+			expectPolicies: []abacPolicy{
+				{
+					Group:    "system:masters",
+					Resource: "*",
+				},
+			},
+		},
+		{
+			caseName: "policies without abac mode is rejected",
+			resourceData: map[string]interface{}{
+				"authorization": []interface{}{
+					map[string]interface{}{
+						"mode": "rbac",
+						"policies": []interface{}{
+							map[string]interface{}{
+								"user": "alice",
+							},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, testcase := range testcases {
 		t.Run(testcase.caseName, func(t *testing.T) {
 			d := &dummyResourceData{values: testcase.resourceData}
-			config, err := parseResourceAuthorization(d)
+			config, policies, err := parseResourceAuthorization(d)
+			if testcase.expectErr {
+				assert.Error(t, err)
+				return
+			}
 			assert.NoError(t, err)
 			assert.EqualValues(t, testcase.expectConfig, config)
+			assert.EqualValues(t, testcase.expectPolicies, policies)
 		})
 	}
 }
 
+func TestABACPolicyDocument(t *testing.T) {
+	doc, err := abacPolicyDocument([]abacPolicy{
+		{User: "alice", Namespace: "default", Resource: "pods", Readonly: true},
+		{Group: "system:masters", Resource: "*", APIGroup: "*"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"apiVersion":"abac.authorization.kubernetes.io/v1beta1","kind":"Policy","spec":{"user":"alice","namespace":"default","resource":"pods","readonly":true}}
+{"apiVersion":"abac.authorization.kubernetes.io/v1beta1","kind":"Policy","spec":{"group":"system:masters","resource":"*","apiGroup":"*"}}
+`, doc)
+}
+
+func TestAuthzModeHasABAC(t *testing.T) {
+	cases := []struct {
+		mode   string
+		expect bool
+	}{
+		{mode: "abac", expect: true},
+		{mode: "rbac,abac", expect: true},
+		{mode: "abac, rbac", expect: true},
+		{mode: "rbac", expect: false},
+		{mode: "", expect: false},
+	}
+	for _, testcase := range cases {
+		assert.Equal(t, testcase.expect, authzModeHasABAC(testcase.mode), testcase.mode)
+	}
+}
+
 func TestParseResourceIgnoreDockerVersion(t *testing.T) {
 	d := &dummyResourceData{values: map[string]interface{}{"ignore_docker_version": true}}
 	ignore, err := parseResourceIgnoreDockerVersion(d)
@@ -716,6 +1070,106 @@ func TestParseResourceKubernetesVersion(t *testing.T) {
 	assert.EqualValues(t, "1.8.9", version)
 }
 
+func TestParseResourceVersionServiceOptionsOverride(t *testing.T) {
+	d := &dummyResourceData{
+		values: map[string]interface{}{
+			"version_service_options_override": []interface{}{
+				map[string]interface{}{
+					"kubernetes_version": "v1.17",
+					"kube_api_extra_args": map[string]interface{}{
+						"enable-admission-plugins": "NodeRestriction",
+					},
+					"kubelet_extra_args": map[string]interface{}{
+						"resolv-conf": "/etc/resolv.conf",
+					},
+				},
+			},
+		},
+	}
+	overrides, err := parseResourceVersionServiceOptionsOverride(d)
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[string]serviceOptionsSet{
+		"v1.17": {
+			KubeAPI: map[string]string{
+				"enable-admission-plugins": "NodeRestriction",
+			},
+			Kubelet: map[string]string{
+				"resolv-conf": "/etc/resolv.conf",
+			},
+		},
+	}, overrides)
+}
+
+func TestResolveServiceExtraArgs(t *testing.T) {
+	overrides := map[string]serviceOptionsSet{
+		"v1.17": {
+			KubeAPI: map[string]string{
+				"enable-admission-plugins": "NodeRestriction",
+			},
+		},
+	}
+
+	cases := []struct {
+		caseName  string
+		version   string
+		overrides map[string]serviceOptionsSet
+		userArgs  map[string]string
+		expect    map[string]string
+	}{
+		{
+			caseName: "default table merge, user wins",
+			version:  "v1.16",
+			userArgs: map[string]string{
+				"enable-admission-plugins": "custom",
+			},
+			expect: map[string]string{
+				"allow-privileged":         "true",
+				"anonymous-auth":           "false",
+				"bind-address":             "0.0.0.0",
+				"enable-admission-plugins": "custom",
+				"storage-backend":          "etcd3",
+				"tls-cipher-suites":        "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+			},
+		},
+		{
+			caseName: "full rancher version string normalizes to its minor version",
+			version:  "v1.16.3-rancher1-1",
+			userArgs: map[string]string{},
+			expect: map[string]string{
+				"allow-privileged":         "true",
+				"anonymous-auth":           "false",
+				"bind-address":             "0.0.0.0",
+				"enable-admission-plugins": "ServiceAccount,NamespaceLifecycle,LimitRanger,PodSecurityPolicy",
+				"storage-backend":          "etcd3",
+				"tls-cipher-suites":        "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+			},
+		},
+		{
+			caseName:  "override table takes precedence over defaults",
+			version:   "v1.17",
+			overrides: overrides,
+			userArgs:  map[string]string{},
+			expect: map[string]string{
+				"enable-admission-plugins": "NodeRestriction",
+			},
+		},
+		{
+			caseName: "unknown version with no user args",
+			version:  "v1.99",
+			expect:   nil,
+		},
+	}
+
+	for _, testcase := range cases {
+		t.Run(testcase.caseName, func(t *testing.T) {
+			resolved := resolveServiceExtraArgs(testcase.version, testcase.overrides, func(s serviceOptionsSet) map[string]string {
+				return s.KubeAPI
+			}, testcase.userArgs)
+			assert.EqualValues(t, testcase.expect, resolved)
+		})
+	}
+}
+
 func TestParseResourcePrivateRegistries(t *testing.T) {
 	testcases := []struct {
 		caseName     string
@@ -850,12 +1304,108 @@ func TestParseResourceCloudProvider(t *testing.T) {
 	}
 }
 
+func TestParseResourceWaitForReady(t *testing.T) {
+	testcases := []struct {
+		caseName     string
+		resourceData map[string]interface{}
+		expectConfig *waitForReadyConfig
+	}{
+		{
+			caseName:     "unset",
+			resourceData: map[string]interface{}{},
+			expectConfig: nil,
+		},
+		{
+			caseName: "defaults",
+			resourceData: map[string]interface{}{
+				"wait_for_ready": []interface{}{
+					map[string]interface{}{
+						"enabled": true,
+					},
+				},
+			},
+			expectConfig: &waitForReadyConfig{
+				Enabled:      true,
+				Timeout:      defaultWaitForReadyTimeout,
+				PollInterval: defaultWaitForReadyPollInterval,
+			},
+		},
+		{
+			caseName: "all fields",
+			resourceData: map[string]interface{}{
+				"wait_for_ready": []interface{}{
+					map[string]interface{}{
+						"enabled":         true,
+						"timeout":         "20m",
+						"poll_interval":   "10s",
+						"min_ready_nodes": 3,
+					},
+				},
+			},
+			expectConfig: &waitForReadyConfig{
+				Enabled:       true,
+				Timeout:       20 * time.Minute,
+				PollInterval:  10 * time.Second,
+				MinReadyNodes: 3,
+			},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.caseName, func(t *testing.T) {
+			d := &dummyResourceData{values: testcase.resourceData}
+			config, err := parseResourceWaitForReady(d)
+			assert.NoError(t, err)
+			assert.EqualValues(t, testcase.expectConfig, config)
+		})
+	}
+}
+
+func TestParseResourceRKEConfigYAML(t *testing.T) {
+	const yamlBody = `
+cluster_name: yaml-cluster
+kubernetes_version: "1.8.9"
+ignore_docker_version: true
+nodes:
+- address: 10.0.0.1
+  role:
+  - controlplane
+  - worker
+  - etcd
+`
+
+	d := &dummyResourceData{
+		values: map[string]interface{}{
+			"yaml_body":    yamlBody,
+			"cluster_name": "tf-cluster",
+		},
+	}
+
+	rkeConfig, _, err := parseResourceRKEConfigYAML(d)
+	assert.NoError(t, err)
+
+	expect := &v3.RancherKubernetesEngineConfig{
+		ClusterName:         "tf-cluster",
+		Version:             "1.8.9",
+		IgnoreDockerVersion: true,
+		Nodes: []v3.RKEConfigNode{
+			{
+				Address: "10.0.0.1",
+				Role:    []string{"controlplane", "worker", "etcd"},
+			},
+		},
+	}
+	assert.EqualValues(t, expect, rkeConfig)
+}
+
 func TestClusterToState(t *testing.T) {
 
 	testcases := []struct {
-		caseName string
-		cluster  *cluster.Cluster
-		state    map[string]interface{}
+		caseName      string
+		cluster       *cluster.Cluster
+		networkAddons networkAddonState
+		abacPolicies  []abacPolicy
+		state         map[string]interface{}
 	}{
 		{
 			caseName: "all fields",
@@ -874,6 +1424,8 @@ func TestClusterToState(t *testing.T) {
 							SSHAgentAuth:     true,
 							SSHKey:           "ssh_key",
 							SSHKeyPath:       "ssh_key_path",
+							SSHCert:          "ssh_cert",
+							SSHCertPath:      "ssh_cert_path",
 							Labels: map[string]string{
 								"foo": "foo",
 								"bar": "bar",
@@ -889,6 +1441,7 @@ func TestClusterToState(t *testing.T) {
 									"bar": "foo",
 								},
 								ExtraBinds: []string{"/bind1", "/bind2"},
+								ExtraEnv:   []string{"FOO=bar"},
 							},
 							ExternalURLs: []string{
 								"https://ext1.example.com",
@@ -909,7 +1462,9 @@ func TestClusterToState(t *testing.T) {
 								ExtraBinds: []string{"/bind1", "/bind2"},
 							},
 							ServiceClusterIPRange: "10.240.0.0/16",
+							ServiceNodePortRange:  "30000-32767",
 							PodSecurityPolicy:     true,
+							AlwaysPullImages:      true,
 						},
 						KubeController: v3.KubeControllerService{
 							BaseService: v3.BaseService{
@@ -1124,6 +1679,8 @@ func TestClusterToState(t *testing.T) {
 						"ssh_agent_auth":    true,
 						"ssh_key":           "ssh_key",
 						"ssh_key_path":      "ssh_key_path",
+						"ssh_cert":          "ssh_cert",
+						"ssh_cert_path":     "ssh_cert_path",
 						"labels": map[string]string{
 							"foo": "foo",
 							"bar": "bar",
@@ -1138,14 +1695,23 @@ func TestClusterToState(t *testing.T) {
 							"bar": "foo",
 						},
 						"extra_binds": []string{"/bind1", "/bind2"},
+						"extra_env":   []string{"FOO=bar"},
 						"external_urls": []string{
 							"https://ext1.example.com",
 							"https://ext2.example.com",
 						},
-						"ca_cert": "ca_cert",
-						"cert":    "cert",
-						"key":     "key",
-						"path":    "path",
+						"ca_cert":       "ca_cert",
+						"cert":          "cert",
+						"key":           "key",
+						"path":          "path",
+						"snapshot":      false,
+						"retention":     "",
+						"creation":      "",
+						"backup_config": []interface{}{},
+						"resolved_extra_args": map[string]string{
+							"foo": "bar",
+							"bar": "foo",
+						},
 					},
 				},
 				"services_kube_api": []interface{}{
@@ -1156,8 +1722,15 @@ func TestClusterToState(t *testing.T) {
 							"bar": "foo",
 						},
 						"extra_binds":              []string{"/bind1", "/bind2"},
+						"extra_env":                []string(nil),
 						"service_cluster_ip_range": "10.240.0.0/16",
+						"service_node_port_range":  "30000-32767",
 						"pod_security_policy":      true,
+						"always_pull_images":       true,
+						"resolved_extra_args": map[string]string{
+							"foo": "bar",
+							"bar": "foo",
+						},
 					},
 				},
 				"services_kube_controller": []interface{}{
@@ -1168,8 +1741,13 @@ func TestClusterToState(t *testing.T) {
 							"bar": "foo",
 						},
 						"extra_binds":              []string{"/bind1", "/bind2"},
+						"extra_env":                []string(nil),
 						"cluster_cidr":             "10.200.0.0/8",
 						"service_cluster_ip_range": "10.240.0.0/16",
+						"resolved_extra_args": map[string]string{
+							"foo": "bar",
+							"bar": "foo",
+						},
 					},
 				},
 				"services_scheduler": []interface{}{
@@ -1180,6 +1758,11 @@ func TestClusterToState(t *testing.T) {
 							"bar": "foo",
 						},
 						"extra_binds": []string{"/bind1", "/bind2"},
+						"extra_env":   []string(nil),
+						"resolved_extra_args": map[string]string{
+							"foo": "bar",
+							"bar": "foo",
+						},
 					},
 				},
 				"services_kubelet": []interface{}{
@@ -1190,10 +1773,15 @@ func TestClusterToState(t *testing.T) {
 							"bar": "foo",
 						},
 						"extra_binds":           []string{"/bind1", "/bind2"},
+						"extra_env":             []string(nil),
 						"cluster_domain":        "example.com",
 						"infra_container_image": "alpine:latest",
 						"cluster_dns_server":    "192.2.0.1",
 						"fail_swap_on":          true,
+						"resolved_extra_args": map[string]string{
+							"foo": "bar",
+							"bar": "foo",
+						},
 					},
 				},
 				"services_kubeproxy": []interface{}{
@@ -1204,6 +1792,11 @@ func TestClusterToState(t *testing.T) {
 							"bar": "foo",
 						},
 						"extra_binds": []string{"/bind1", "/bind2"},
+						"extra_env":   []string(nil),
+						"resolved_extra_args": map[string]string{
+							"foo": "bar",
+							"bar": "foo",
+						},
 					},
 				},
 				"network": []interface{}{
@@ -1366,10 +1959,247 @@ func TestClusterToState(t *testing.T) {
 	for _, testcase := range testcases {
 		t.Run(testcase.caseName, func(t *testing.T) {
 			d := &dummyStateBuilder{values: map[string]interface{}{}}
-			err := clusterToState(testcase.cluster, d)
+			err := clusterToState(testcase.cluster, d, nil, testcase.networkAddons, testcase.abacPolicies)
 			assert.NoError(t, err)
 			assert.EqualValues(t, testcase.state, d.values)
 		})
 	}
+}
 
-}
\ No newline at end of file
+// TestClusterToStateNetworkRoundTrip checks that clusterToState decodes the cilium and
+// multus sub-blocks back into state rather than flattening them to plugin+options, so a
+// terraform plan right after apply doesn't show the block as removed.
+func TestClusterToStateNetworkRoundTrip(t *testing.T) {
+	t.Run("multus", func(t *testing.T) {
+		c := &cluster.Cluster{
+			RancherKubernetesEngineConfig: v3.RancherKubernetesEngineConfig{
+				Network: v3.NetworkConfig{
+					Plugin: "multus",
+					Options: map[string]string{
+						"multus-version": "3.4.1",
+					},
+				},
+			},
+		}
+		networkAddons := networkAddonState{
+			MultusAttachmentDefinitions: []interface{}{
+				map[string]interface{}{
+					"name":      "macvlan-conf",
+					"namespace": "default",
+					"cni_type":  "macvlan",
+					"master":    "eth1",
+					"mode":      "bridge",
+					"ipam": []interface{}{
+						map[string]interface{}{
+							"type":  "whereabouts",
+							"range": "192.168.2.0/24",
+						},
+					},
+				},
+			},
+		}
+
+		d := &dummyStateBuilder{values: map[string]interface{}{}}
+		err := clusterToState(c, d, nil, networkAddons, nil)
+		assert.NoError(t, err)
+
+		network := d.values["network"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{
+				"version":               "3.4.1",
+				"attachment_definition": networkAddons.MultusAttachmentDefinitions,
+			},
+		}, network["multus"])
+	})
+
+	t.Run("cilium", func(t *testing.T) {
+		c := &cluster.Cluster{
+			RancherKubernetesEngineConfig: v3.RancherKubernetesEngineConfig{
+				Network: v3.NetworkConfig{
+					Plugin: "cilium",
+					Options: map[string]string{
+						"cilium-version":        "1.4.2",
+						"ipam":                  "kubernetes",
+						"tunnel":                "vxlan",
+						"enable-bpf-masquerade": "true",
+						"enable-hubble":         "false",
+					},
+				},
+			},
+		}
+		networkAddons := networkAddonState{
+			CiliumEtcdNodeSelector: map[string]string{
+				"cilium-etcd": "true",
+			},
+		}
+
+		d := &dummyStateBuilder{values: map[string]interface{}{}}
+		err := clusterToState(c, d, nil, networkAddons, nil)
+		assert.NoError(t, err)
+
+		network := d.values["network"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{
+				"version":               "1.4.2",
+				"ipam":                  "kubernetes",
+				"tunnel":                "vxlan",
+				"enable_bpf_masquerade": true,
+				"enable_hubble":         false,
+				"etcd": []interface{}{
+					map[string]interface{}{
+						"node_selector": map[string]string{
+							"cilium-etcd": "true",
+						},
+					},
+				},
+			},
+		}, network["cilium"])
+	})
+}
+
+// TestClusterToStateABACPoliciesRoundTrip checks that clusterToState writes
+// authorization.policies back into state, since v3.AuthzConfig has nowhere to carry
+// them and a cluster with ABAC policies configured would otherwise show a perpetual
+// diff on every terraform plan.
+func TestClusterToStateABACPoliciesRoundTrip(t *testing.T) {
+	c := &cluster.Cluster{
+		RancherKubernetesEngineConfig: v3.RancherKubernetesEngineConfig{
+			Authorization: v3.AuthzConfig{
+				Mode: "rbac,abac",
+			},
+		},
+	}
+	policies := []abacPolicy{
+		{
+			User:     "admin",
+			Resource: "*",
+			APIGroup: "*",
+		},
+		{
+			Group:           "system:masters",
+			NonResourcePath: "*",
+			Readonly:        true,
+		},
+	}
+
+	d := &dummyStateBuilder{values: map[string]interface{}{}}
+	err := clusterToState(c, d, nil, networkAddonState{}, policies)
+	assert.NoError(t, err)
+
+	authorization := d.values["authorization"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"user":             "admin",
+			"group":            "",
+			"namespace":        "",
+			"resource":         "*",
+			"api_group":        "*",
+			"nonresource_path": "",
+			"readonly":         false,
+		},
+		map[string]interface{}{
+			"user":             "",
+			"group":            "system:masters",
+			"namespace":        "",
+			"resource":         "",
+			"api_group":        "",
+			"nonresource_path": "*",
+			"readonly":         true,
+		},
+	}, authorization["policies"])
+}
+
+const dummyGeneratedKubeConfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster:
+    server: https://192.2.0.1:6443
+contexts:
+- name: local
+  context:
+    cluster: local
+    user: local
+current-context: local
+users:
+- name: local
+  user:
+    token: generated-token
+`
+
+func TestMergeKubeConfig(t *testing.T) {
+	generated, err := loadKubeConfig(dummyGeneratedKubeConfig)
+	assert.NoError(t, err)
+
+	base, err := loadKubeConfigFile("")
+	assert.NoError(t, err)
+	base.Clusters["other"] = &api.Cluster{Server: "https://10.0.0.1:6443"}
+	base.AuthInfos["other"] = &api.AuthInfo{Token: "other-token"}
+	base.Contexts["other"] = &api.Context{Cluster: "other", AuthInfo: "other"}
+
+	err = mergeKubeConfig(base, generated, "my-cluster")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://192.2.0.1:6443", base.Clusters["my-cluster"].Server)
+	assert.Equal(t, "generated-token", base.AuthInfos["my-cluster"].Token)
+	assert.Equal(t, &api.Context{Cluster: "my-cluster", AuthInfo: "my-cluster"}, base.Contexts["my-cluster"])
+
+	// the context that was already in the file must survive untouched
+	assert.Equal(t, "https://10.0.0.1:6443", base.Clusters["other"].Server)
+
+	removeKubeConfigContext(base, "my-cluster")
+	assert.Nil(t, base.Clusters["my-cluster"])
+	assert.Nil(t, base.AuthInfos["my-cluster"])
+	assert.Nil(t, base.Contexts["my-cluster"])
+	assert.Equal(t, "https://10.0.0.1:6443", base.Clusters["other"].Server)
+}
+
+const dummyAdminKubeConfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster:
+    server: https://192.2.0.1:6443
+    certificate-authority-data: Y2FkYXRh
+contexts:
+- name: local
+  context:
+    cluster: local
+    user: local
+current-context: local
+users:
+- name: local
+  user:
+    client-certificate-data: Y2VydGRhdGE=
+    client-key-data: a2V5ZGF0YQ==
+`
+
+func TestAdminKubeConfigState(t *testing.T) {
+	c := &cluster.Cluster{
+		Certificates: map[string]pki.CertificatePKI{
+			pki.KubeAdminCertName: {
+				Config: dummyAdminKubeConfig,
+			},
+		},
+	}
+
+	state, err := adminKubeConfigState(c)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"kube_config_yaml": dummyAdminKubeConfig,
+		"ca_crt":           "cadata",
+		"client_cert":      "certdata",
+		"client_key":       "keydata",
+		"api_server_url":   "https://192.2.0.1:6443",
+	}, state)
+}
+
+func TestAdminKubeConfigStateNoAdminCert(t *testing.T) {
+	c := &cluster.Cluster{}
+
+	state, err := adminKubeConfigState(c)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
@@ -0,0 +1,9 @@
+package rke
+
+import "fmt"
+
+// errInvalidValue builds the standard "invalid value" ValidateFunc error, listing the
+// allowed values so the plan-time diagnostic tells the user exactly what to change.
+func errInvalidValue(key string, got string, allowed []string) error {
+	return fmt.Errorf("%s: invalid value %q, must be one of %v", key, got, allowed)
+}
@@ -0,0 +1,249 @@
+package rke
+
+import "regexp"
+
+// minorK8sVersionPattern extracts the "vMAJOR.MINOR" prefix off a full RKE/Kubernetes
+// version string such as "v1.16.3-rancher1-1", so it can be used as a lookup key into
+// the per-minor-version tables below.
+var minorK8sVersionPattern = regexp.MustCompile(`^(v\d+\.\d+)`)
+
+// minorK8sVersion normalizes a full Kubernetes version (e.g. "v1.16.3-rancher1-1") down
+// to its "vMAJOR.MINOR" form (e.g. "v1.16"). Versions that don't match the expected
+// "vMAJOR.MINOR..." shape are returned unchanged so lookups against them simply miss.
+func minorK8sVersion(version string) string {
+	if m := minorK8sVersionPattern.FindString(version); m != "" {
+		return m
+	}
+	return version
+}
+
+// serviceOptionsSet is the set of default extra_args RKE applies for a given
+// Kubernetes version, one map per service. It mirrors the shape of RKE's own
+// per-version service options metadata.
+type serviceOptionsSet struct {
+	Etcd           map[string]string
+	KubeAPI        map[string]string
+	KubeController map[string]string
+	Scheduler      map[string]string
+	Kubelet        map[string]string
+	Kubeproxy      map[string]string
+}
+
+// defaultK8sVersionServiceOptions is a small embedded copy of the per-version default
+// service args RKE ships, used to compute resolved_extra_args for display in state.
+// It is not exhaustive; version_service_options_override lets operators extend or
+// replace entries for versions not covered here (e.g. air-gapped or custom builds).
+var defaultK8sVersionServiceOptions = map[string]serviceOptionsSet{
+	"v1.10": {
+		KubeAPI: map[string]string{
+			"allow-privileged":         "true",
+			"anonymous-auth":           "false",
+			"enable-admission-plugins": "ServiceAccount,NamespaceLifecycle,LimitRanger",
+			"storage-backend":          "etcd3",
+		},
+		KubeController: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Scheduler: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Kubelet: map[string]string{
+			"cgroups-per-qos": "true",
+		},
+		Kubeproxy: map[string]string{
+			"proxy-mode": "iptables",
+		},
+	},
+	"v1.11": {
+		KubeAPI: map[string]string{
+			"allow-privileged":         "true",
+			"anonymous-auth":           "false",
+			"enable-admission-plugins": "ServiceAccount,NamespaceLifecycle,LimitRanger",
+			"storage-backend":          "etcd3",
+		},
+		KubeController: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Scheduler: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Kubelet: map[string]string{
+			"cgroups-per-qos": "true",
+		},
+		Kubeproxy: map[string]string{
+			"proxy-mode": "iptables",
+		},
+	},
+	"v1.12": {
+		KubeAPI: map[string]string{
+			"allow-privileged":         "true",
+			"anonymous-auth":           "false",
+			"enable-admission-plugins": "ServiceAccount,NamespaceLifecycle,LimitRanger,DefaultStorageClass",
+			"storage-backend":          "etcd3",
+			"tls-cipher-suites":        "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		},
+		KubeController: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Scheduler: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Kubelet: map[string]string{
+			"cgroups-per-qos": "true",
+		},
+		Kubeproxy: map[string]string{
+			"proxy-mode": "iptables",
+		},
+	},
+	"v1.13": {
+		KubeAPI: map[string]string{
+			"allow-privileged":         "true",
+			"anonymous-auth":           "false",
+			"enable-admission-plugins": "ServiceAccount,NamespaceLifecycle,LimitRanger,DefaultStorageClass",
+			"storage-backend":          "etcd3",
+			"tls-cipher-suites":        "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		},
+		KubeController: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Scheduler: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Kubelet: map[string]string{
+			"cgroups-per-qos":                 "true",
+			"kubelet-preferred-address-types": "InternalIP,ExternalIP,Hostname",
+		},
+		Kubeproxy: map[string]string{
+			"proxy-mode": "iptables",
+		},
+	},
+	"v1.14": {
+		KubeAPI: map[string]string{
+			"allow-privileged":         "true",
+			"anonymous-auth":           "false",
+			"bind-address":             "0.0.0.0",
+			"enable-admission-plugins": "ServiceAccount,NamespaceLifecycle,LimitRanger,DefaultStorageClass",
+			"storage-backend":          "etcd3",
+			"tls-cipher-suites":        "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		},
+		KubeController: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Scheduler: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Kubelet: map[string]string{
+			"cgroups-per-qos":                 "true",
+			"kubelet-preferred-address-types": "InternalIP,ExternalIP,Hostname",
+			"resolv-conf":                     "/etc/resolv.conf",
+		},
+		Kubeproxy: map[string]string{
+			"proxy-mode": "iptables",
+		},
+	},
+	"v1.15": {
+		KubeAPI: map[string]string{
+			"allow-privileged":         "true",
+			"anonymous-auth":           "false",
+			"bind-address":             "0.0.0.0",
+			"enable-admission-plugins": "ServiceAccount,NamespaceLifecycle,LimitRanger",
+			"storage-backend":          "etcd3",
+			"tls-cipher-suites":        "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		},
+		KubeController: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Scheduler: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Kubelet: map[string]string{
+			"cgroups-per-qos":                 "true",
+			"kubelet-preferred-address-types": "InternalIP,ExternalIP,Hostname",
+			"resolv-conf":                     "/etc/resolv.conf",
+		},
+		Kubeproxy: map[string]string{
+			"proxy-mode": "iptables",
+		},
+	},
+	"v1.16": {
+		KubeAPI: map[string]string{
+			"allow-privileged":         "true",
+			"anonymous-auth":           "false",
+			"bind-address":             "0.0.0.0",
+			"enable-admission-plugins": "ServiceAccount,NamespaceLifecycle,LimitRanger,PodSecurityPolicy",
+			"storage-backend":          "etcd3",
+			"tls-cipher-suites":        "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+		},
+		KubeController: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Scheduler: map[string]string{
+			"address": "0.0.0.0",
+		},
+		Kubelet: map[string]string{
+			"cgroups-per-qos":                 "true",
+			"kubelet-preferred-address-types": "InternalIP,ExternalIP,Hostname",
+			"resolv-conf":                     "/etc/resolv.conf",
+			"volume-plugin-dir":               "/var/lib/kubelet/volumeplugins",
+		},
+		Kubeproxy: map[string]string{
+			"proxy-mode": "iptables",
+		},
+	},
+}
+
+// mergeExtraArgs merges version defaults under the user-supplied extra_args, with the
+// user's values winning on key collisions.
+func mergeExtraArgs(defaults, user map[string]string) map[string]string {
+	if len(defaults) == 0 && len(user) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(defaults)+len(user))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range user {
+		merged[k] = v
+	}
+	return merged
+}
+
+// resolveServiceExtraArgs looks up the default extra_args for a given Kubernetes
+// version and service (overrides, if present for that version, take precedence over
+// defaultK8sVersionServiceOptions), and merges them under the user's extra_args.
+func resolveServiceExtraArgs(version string, overrides map[string]serviceOptionsSet, pick func(serviceOptionsSet) map[string]string, userArgs map[string]string) map[string]string {
+	minor := minorK8sVersion(version)
+	set, ok := overrides[minor]
+	if !ok {
+		set, ok = defaultK8sVersionServiceOptions[minor]
+	}
+	if !ok {
+		return mergeExtraArgs(nil, userArgs)
+	}
+	return mergeExtraArgs(pick(set), userArgs)
+}
+
+// parseResourceVersionServiceOptionsOverride parses the version_service_options_override
+// blocks into a map keyed by kubernetes_version, so operators can extend or replace the
+// embedded default table for versions it doesn't cover.
+func parseResourceVersionServiceOptionsOverride(d rkeResourceGetter) (map[string]serviceOptionsSet, error) {
+	v, ok := d.GetOk("version_service_options_override")
+	if !ok {
+		return nil, nil
+	}
+	raw := v.([]interface{})
+	overrides := make(map[string]serviceOptionsSet, len(raw))
+	for _, item := range raw {
+		m := item.(map[string]interface{})
+		version := mapGetString(m, "kubernetes_version")
+		overrides[version] = serviceOptionsSet{
+			Etcd:           mapGetStringMap(m, "etcd_extra_args"),
+			KubeAPI:        mapGetStringMap(m, "kube_api_extra_args"),
+			KubeController: mapGetStringMap(m, "kube_controller_extra_args"),
+			Scheduler:      mapGetStringMap(m, "scheduler_extra_args"),
+			Kubelet:        mapGetStringMap(m, "kubelet_extra_args"),
+			Kubeproxy:      mapGetStringMap(m, "kubeproxy_extra_args"),
+		}
+	}
+	return overrides, nil
+}
@@ -0,0 +1,132 @@
+package rke
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rancher/rke/cluster"
+	"github.com/rancher/rke/pki"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// waitForReadyConfig drives the optional post-ClusterUp readiness gate: it blocks
+// resourceRKEClusterCreate until the cluster looks usable, or surfaces the last
+// failing check once Timeout elapses.
+type waitForReadyConfig struct {
+	Enabled       bool
+	Timeout       time.Duration
+	PollInterval  time.Duration
+	MinReadyNodes int
+}
+
+const (
+	defaultWaitForReadyTimeout      = 10 * time.Minute
+	defaultWaitForReadyPollInterval = 5 * time.Second
+)
+
+// waitForClusterReady polls the freshly provisioned cluster until at least
+// cfg.MinReadyNodes nodes (every node, when unset) and every kube-system
+// DaemonSet/Deployment report ready, or cfg.Timeout elapses. A nil or disabled cfg is
+// a no-op.
+func waitForClusterReady(c *cluster.Cluster, cfg *waitForReadyConfig) error {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	clientset, err := adminClientset(c)
+	if err != nil {
+		return fmt.Errorf("building client-go clientset for wait_for_ready: %s", err)
+	}
+
+	deadline := time.Now().Add(cfg.Timeout)
+	for {
+		checkErr := checkClusterReady(clientset, cfg.MinReadyNodes)
+		if checkErr == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for cluster to become ready: %s", checkErr)
+		}
+		time.Sleep(cfg.PollInterval)
+	}
+}
+
+// adminClientset builds a client-go clientset from the admin kubeconfig RKE renders
+// into the cluster's certificate bundle during cluster.ClusterUp.
+func adminClientset(c *cluster.Cluster) (kubernetes.Interface, error) {
+	entry, ok := c.Certificates[pki.KubeAdminCertName]
+	if !ok {
+		return nil, fmt.Errorf("cluster has no %s certificate", pki.KubeAdminCertName)
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(entry.Config))
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(restConfig)
+}
+
+// checkClusterReady runs the node/DaemonSet/Deployment checks and returns a retryable
+// error describing the first one that's still failing, so a wait_for_ready timeout
+// points at the addon or node stuck in rollout rather than a generic "not ready".
+func checkClusterReady(clientset kubernetes.Interface, minReadyNodes int) error {
+	nodes, err := clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing nodes: %s", err)
+	}
+
+	readyNodes := 0
+	for _, n := range nodes.Items {
+		if nodeIsReady(n) {
+			readyNodes++
+		}
+	}
+	// A zero/unset min_ready_nodes means "every node", not "none required".
+	requiredReadyNodes := minReadyNodes
+	if requiredReadyNodes == 0 {
+		requiredReadyNodes = len(nodes.Items)
+	}
+	if readyNodes < requiredReadyNodes {
+		return fmt.Errorf("%d of %d required nodes are Ready", readyNodes, requiredReadyNodes)
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(kubeSystemNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing kube-system daemonsets: %s", err)
+	}
+	for _, ds := range daemonSets.Items {
+		if ds.Status.DesiredNumberScheduled != ds.Status.NumberReady {
+			return fmt.Errorf("daemonset %s has %d of %d desired pods ready", ds.Name, ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)
+		}
+	}
+
+	deployments, err := clientset.AppsV1().Deployments(kubeSystemNamespace).List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing kube-system deployments: %s", err)
+	}
+	for _, dep := range deployments.Items {
+		if dep.Status.AvailableReplicas < dep.Status.UpdatedReplicas {
+			return fmt.Errorf("deployment %s has %d of %d updated replicas available", dep.Name, dep.Status.AvailableReplicas, dep.Status.UpdatedReplicas)
+		}
+		if oldReplicas := dep.Status.Replicas - dep.Status.UpdatedReplicas; oldReplicas > 0 {
+			return fmt.Errorf("deployment %s has %d old replicas pending termination", dep.Name, oldReplicas)
+		}
+	}
+
+	return nil
+}
+
+const kubeSystemNamespace = "kube-system"
+
+func nodeIsReady(n corev1.Node) bool {
+	for _, cond := range n.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}